@@ -0,0 +1,188 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// accessLogLayouts are the log_format strings of the access log Formats
+// other than FormatAccessLog.
+var accessLogLayouts = map[Format]AccessLogFormat{
+	FormatCommonLog:   AccessLogCommon,
+	FormatCombinedLog: AccessLogCombined,
+}
+
+var (
+	accessLogVariable = regexp.MustCompile(`\$([A-Za-z0-9_]+)`)
+	statusPattern     = regexp.MustCompile(`^[1-5][0-9xX]{2}$`)
+)
+
+// accessLog is a compiled access log layout.
+type accessLog struct {
+	pattern *regexp.Regexp
+	names   []string // of the pattern's groups
+}
+
+// compileAccessLog turns an nginx log_format string into a pattern with a
+// group for each variable. A variable followed by a space, or ending the
+// layout, holds no spaces; any other runs up to the text that follows it,
+// such as the closing quote of "$request".
+func compileAccessLog(layout AccessLogFormat) (*accessLog, error) {
+	s := string(layout)
+	a := &accessLog{}
+	var b strings.Builder
+	b.WriteByte('^')
+	last := 0
+	for _, m := range accessLogVariable.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(regexp.QuoteMeta(s[last:m[0]]))
+		if m[1] == len(s) || s[m[1]] == ' ' {
+			b.WriteString(`(\S*)`)
+		} else {
+			b.WriteString(`(.*?)`)
+		}
+		a.names = append(a.names, s[m[2]:m[3]])
+		last = m[1]
+	}
+	b.WriteString(regexp.QuoteMeta(s[last:]))
+	b.WriteByte('$')
+	if len(a.names) == 0 {
+		return nil, fmt.Errorf("invalid access log format %q: no variables", s)
+	}
+	a.pattern = regexp.MustCompile(b.String())
+	return a, nil
+}
+
+// parse parses an access log line into a field for each variable, named
+// without its "$". "-" stands for a missing value and is left out. The
+// request line is also split into method, path and protocol.
+func (a *accessLog) parse(record string) (fields, bool) {
+	m := a.pattern.FindStringSubmatch(record)
+	if m == nil {
+		return nil, false
+	}
+	f := fields{}
+	for i, name := range a.names {
+		if m[i+1] != "-" {
+			f.set(name, m[i+1])
+		}
+	}
+	if request, ok := f["request"].(string); ok {
+		if parts := strings.Fields(request); len(parts) == 3 {
+			f["method"], f["path"], f["protocol"] = parts[0], parts[1], parts[2]
+		}
+	}
+	return f, true
+}
+
+// has reports whether the layout has the variable.
+func (a *accessLog) has(name string) bool {
+	for _, n := range a.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// compileAccessLogFlags compiles the access log layout and adds the Status,
+// SlowerThan and PathPrefix filters to the Where conditions. An
+// AccessLogFormat implies FormatAccessLog.
+func (e *engine) compileAccessLogFlags() error {
+	if e.AccessLog != "" && e.Format == FormatText {
+		e.Format = FormatAccessLog
+	}
+	layout, preset := accessLogLayouts[e.Format]
+	switch {
+	case e.Format == FormatAccessLog && e.AccessLog == "":
+		return errors.New("FormatAccessLog needs an AccessLogFormat")
+	case e.AccessLog != "" && e.Format != FormatAccessLog:
+		return errors.New("AccessLogFormat needs FormatAccessLog")
+	case e.Format == FormatAccessLog:
+		layout = e.AccessLog
+	case !preset:
+		if len(e.Statuses) > 0 || e.SlowerThan > 0 || len(e.PathPrefixes) > 0 || e.Output == OutputSummary {
+			return errors.New("Status, SlowerThan, PathPrefix and OutputSummary need an access log Format")
+		}
+		return nil
+	}
+	var err error
+	if e.accessLog, err = compileAccessLog(layout); err != nil {
+		return err
+	}
+	if len(e.Statuses) > 0 {
+		codes := make([]string, len(e.Statuses))
+		for i, status := range e.Statuses {
+			if !statusPattern.MatchString(string(status)) {
+				return fmt.Errorf("invalid status %q: want a code such as 404 or 5xx", string(status))
+			}
+			codes[i] = strings.NewReplacer("x", `\d`, "X", `\d`).Replace(string(status))
+		}
+		e.where = append(e.where, predicate{field: "status", op: "~", re: regexp.MustCompile(`^(?:` + strings.Join(codes, "|") + `)$`)})
+	}
+	if e.SlowerThan > 0 {
+		latency := e.latencyField()
+		if latency == "" {
+			return errors.New("SlowerThan needs $request_time or $upstream_response_time in the AccessLogFormat")
+		}
+		seconds := strconv.FormatFloat(time.Duration(e.SlowerThan).Seconds(), 'f', -1, 64)
+		e.where = append(e.where, predicate{field: latency, op: ">", value: seconds})
+	}
+	if len(e.PathPrefixes) > 0 {
+		prefixes := make([]string, len(e.PathPrefixes))
+		for i, prefix := range e.PathPrefixes {
+			prefixes[i] = regexp.QuoteMeta(string(prefix))
+		}
+		e.where = append(e.where, predicate{field: "path", op: "~", re: regexp.MustCompile(`^(?:` + strings.Join(prefixes, "|") + `)`)})
+	}
+	// A line that isn't a request has no status, latency or path to match
+	if len(e.Statuses) > 0 || e.SlowerThan > 0 || len(e.PathPrefixes) > 0 {
+		e.DropUnparsed = true
+	}
+	return nil
+}
+
+// latencyField returns the field holding the time a request took, in
+// seconds, or "" if the layout has none.
+func (e *engine) latencyField() string {
+	for _, name := range []string{"request_time", "upstream_response_time"} {
+		if e.accessLog.has(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// summarize writes an access log record as "TIME METHOD PATH STATUS
+// LATENCY BYTES", leaving out what the record lacks.
+func (e *engine) summarize(f fields) string {
+	var parts []string
+	add := func(name string) {
+		if v, ok := f[name]; ok {
+			parts = append(parts, fieldString(v))
+		}
+	}
+	add("time_local")
+	add("time_iso8601")
+	if _, ok := f["method"]; ok {
+		add("method")
+		add("path")
+	} else {
+		add("request")
+	}
+	add("status")
+	if latency, ok := f[e.latencyField()]; ok {
+		if seconds, err := strconv.ParseFloat(fieldString(latency), 64); err == nil {
+			parts = append(parts, time.Duration(seconds*float64(time.Second)).Round(time.Millisecond).String())
+		}
+	}
+	if v, ok := f["body_bytes_sent"]; ok {
+		parts = append(parts, fieldString(v)+"B")
+	} else if v, ok := f["bytes_sent"]; ok {
+		parts = append(parts, fieldString(v)+"B")
+	}
+	return strings.Join(parts, " ")
+}
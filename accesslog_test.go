@@ -0,0 +1,147 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	gloo "github.com/gloo-foo/framework"
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+var combinedLog = []string{
+	`10.0.0.1 - - [17/Oct/2026:10:00:01 +0000] "GET /api/users?page=2 HTTP/1.1" 200 512 "-" "curl/8.0"`,
+	`10.0.0.2 - alice [17/Oct/2026:10:00:02 +0000] "POST /api/orders HTTP/1.1" 503 97 "https://shop.example/" "Mozilla/5.0 (X11; Linux)"`,
+	`10.0.0.3 - - [17/Oct/2026:10:00:03 +0000] "GET /static/app.js HTTP/2.0" 404 0 "-" "Mozilla/5.0"`,
+	`not an access log line`,
+	`10.0.0.4 - - [17/Oct/2026:10:00:04 +0000] "GET /api/health HTTP/1.1" 500 - "-" "kube-probe/1.30"`,
+}
+
+const timedLayout = `$remote_addr [$time_local] "$request" $status $body_bytes_sent $request_time`
+
+var timedLog = []string{
+	`10.0.0.1 [17/Oct/2026:10:00:01 +0000] "GET /api/users HTTP/1.1" 200 512 0.045`,
+	`10.0.0.2 [17/Oct/2026:10:00:02 +0000] "GET /api/report HTTP/1.1" 200 20480 2.304`,
+	`10.0.0.3 [17/Oct/2026:10:00:03 +0000] "GET /static/app.js HTTP/1.1" 200 100 1.500`,
+	`10.0.0.4 [17/Oct/2026:10:00:04 +0000] "POST /api/orders HTTP/1.1" 504 0 1.000`,
+}
+
+// ==============================================================================
+// Test Access Logs
+// ==============================================================================
+
+func TestTail_CombinedLogFields(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCombinedLog, command.LineCount(2),
+		command.Field("remote_user"), command.Field("method"), command.Field("path"), command.Field("status"),
+		command.Field("body_bytes_sent"), command.Field("http_user_agent"))).
+		WithStdinLines(combinedLog[:3]...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		`{"remote_user":"alice","method":"POST","path":"/api/orders","status":"503","body_bytes_sent":"97","http_user_agent":"Mozilla/5.0 (X11; Linux)"}`,
+		`{"method":"GET","path":"/static/app.js","status":"404","body_bytes_sent":"0","http_user_agent":"Mozilla/5.0"}`,
+	})
+}
+
+func TestTail_CommonLog(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCommonLog, command.DropUnparsed, command.Where("status>=400"))).
+		WithStdinLines(
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			`127.0.0.1 - - [10/Oct/2000:13:55:37 -0700] "GET /missing HTTP/1.0" 404 -`,
+			combinedLog[0],
+		).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{`127.0.0.1 - - [10/Oct/2000:13:55:37 -0700] "GET /missing HTTP/1.0" 404 -`})
+}
+
+func TestTail_AccessLogStatus(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCombinedLog, command.Status("5xx"), command.Status("404"))).
+		WithStdinLines(combinedLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{combinedLog[1], combinedLog[2], combinedLog[4]})
+}
+
+func TestTail_AccessLogPathPrefix(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCombinedLog, command.PathPrefix("/api/"), command.Status("5xx"))).
+		WithStdinLines(combinedLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{combinedLog[1], combinedLog[4]})
+}
+
+func TestTail_AccessLogSlowerThan(t *testing.T) {
+	result := run.Command(command.Tail(command.AccessLogFormat(timedLayout), command.SlowerThan(time.Second))).
+		WithStdinLines(timedLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{timedLog[1], timedLog[2]})
+}
+
+func TestTail_AccessLogFiltersDropUnparsed(t *testing.T) {
+	// A line that isn't a request can't match a status, latency or path
+	for _, filter := range []any{command.Status("5xx"), command.SlowerThan(500 * time.Millisecond), command.PathPrefix("/")} {
+		result := run.Command(command.Tail(command.AccessLogFormat(timedLayout), filter)).
+			WithStdinLines("garbage line", timedLog[3]).
+			Run()
+
+		assertion.NoError(t, result.Err)
+		assertion.Lines(t, result.Stdout, []string{timedLog[3]})
+	}
+}
+
+func TestTail_AccessLogSummary(t *testing.T) {
+	result := run.Command(command.Tail(command.AccessLogFormat(timedLayout), command.OutputSummary, command.PathPrefix("/api"))).
+		WithStdinLines(timedLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"17/Oct/2026:10:00:01 +0000 GET /api/users 200 45ms 512B",
+		"17/Oct/2026:10:00:02 +0000 GET /api/report 200 2.304s 20480B",
+		"17/Oct/2026:10:00:04 +0000 POST /api/orders 504 1s 0B",
+	})
+}
+
+func TestTail_FollowAccessLogSummary(t *testing.T) {
+	path := writeFile(t, timedLog[0]+"\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.AccessLogFormat(timedLayout),
+		command.OutputSummary, command.Status("5xx"), command.ClockFlag{Clock: clock}))
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, timedLog[1]+"\n"+timedLog[3]+"\n")
+	waitForOutput(t, out, "17/Oct/2026:10:00:04 +0000 POST /api/orders 504 1s 0B\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_AccessLogErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  gloo.Command
+		want string
+	}{
+		{"no layout", command.Tail(command.FormatAccessLog), "FormatAccessLog needs an AccessLogFormat"},
+		{"other format", command.Tail(command.FormatJSON, command.AccessLogFormat(timedLayout)), "AccessLogFormat needs FormatAccessLog"},
+		{"no format", command.Tail(command.Status("5xx")), "need an access log Format"},
+		{"summary", command.Tail(command.FormatLogfmt, command.OutputSummary), "need an access log Format"},
+		{"bad status", command.Tail(command.FormatCommonLog, command.Status("5xxx")), `invalid status "5xxx"`},
+		{"no latency", command.Tail(command.FormatCombinedLog, command.SlowerThan(time.Second)), "SlowerThan needs $request_time"},
+		{"no variables", command.Tail(command.AccessLogFormat("plain")), "no variables"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := run.Quick(tt.cmd)
+
+			assertion.ErrorContains(t, result.Err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"bytes"
+	"io"
+)
+
+// blockSize is how much of a file the backward reader loads at a time.
+const blockSize = 32 * 1024
+
+// backwardReader yields the lines of a seekable input from last to first,
+// reading it in blocks from the end so that only the lines actually
+// returned are ever read. When its decoder has a limit it holds at most
+// about a block more than the limit, however long a line is.
+type backwardReader struct {
+	r          io.ReaderAt
+	size       int64
+	decoder    lineDecoder
+	buf        []byte // unreturned bytes from bufOff, up to end
+	bufOff     int64
+	end        int64 // end of the unreturned lines
+	start      int64 // where the lines to return begin
+	terminated bool  // whether the input ends with a newline
+	done       bool
+}
+
+func newBackwardReader(r io.ReaderAt, size int64, decoder lineDecoder) (*backwardReader, error) {
+	b := &backwardReader{r: r, size: size, decoder: decoder, bufOff: size, end: size}
+	if size == 0 {
+		b.done = true
+		return b, nil
+	}
+	if err := b.fill(); err != nil {
+		return nil, err
+	}
+	if b.buf[len(b.buf)-1] == '\n' {
+		b.terminated = true
+		b.end--
+		b.buf = b.buf[:len(b.buf)-1]
+	}
+	return b, nil
+}
+
+// fill reads the block preceding the buffered bytes.
+func (b *backwardReader) fill() error {
+	n := min(int64(blockSize), b.bufOff)
+	off := b.bufOff - n
+	block := make([]byte, n, n+int64(len(b.buf)))
+	if _, err := b.r.ReadAt(block, off); err != nil && err != io.EOF {
+		return err
+	}
+	b.buf = append(block, b.buf...)
+	b.bufOff = off
+	return nil
+}
+
+// skipTo makes start, the offset of a line, the start of the input, as
+// when the lines before it are a header.
+func (b *backwardReader) skipTo(start int64) {
+	b.start = start
+	if start > b.end || start == b.size {
+		b.done = true
+	}
+}
+
+// prev returns the line before the previously returned one together with
+// the offset where it starts. It returns false once the start of the input
+// has been reached.
+func (b *backwardReader) prev() (string, int64, bool, error) {
+	if b.done {
+		return "", 0, false, nil
+	}
+	if len(b.buf) == 0 && b.bufOff > 0 {
+		if err := b.fill(); err != nil {
+			return "", 0, false, err
+		}
+	}
+	cr := len(b.buf) > 0 && b.buf[len(b.buf)-1] == '\r'
+	dropped := 0 // bytes of the line's end no longer buffered
+	for {
+		if i := bytes.LastIndexByte(b.buf, '\n'); i >= 0 {
+			start := b.bufOff + int64(i) + 1
+			line := b.line(b.buf[i+1:], dropped, cr)
+			b.end = start - 1
+			b.buf = b.buf[:i]
+			b.done = start <= b.start
+			return line, start, true, nil
+		}
+		if b.bufOff == 0 {
+			b.done = true
+			return b.line(b.buf, dropped, cr), 0, true, nil
+		}
+		// The line starts before the buffer, so bytes past the limit from
+		// its start can't be among those held
+		if limit := b.decoder.limit; limit > 0 && len(b.buf) > limit {
+			dropped += len(b.buf) - limit
+			b.buf = b.buf[:limit]
+		}
+		if err := b.fill(); err != nil {
+			return "", 0, false, err
+		}
+	}
+}
+
+// line decodes a line whose end, dropped bytes of it, is no longer
+// buffered. cr reports whether the line's last byte is a carriage return.
+func (b *backwardReader) line(line []byte, dropped int, cr bool) string {
+	if limit := b.decoder.limit; limit > 0 && len(line) > limit {
+		dropped += len(line) - limit
+		line = line[:limit]
+	}
+	return b.decoder.finish(line, dropped, cr)
+}
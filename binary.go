@@ -0,0 +1,172 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrBinary is returned for an input that looks binary when BinaryRefuse is
+// set.
+var ErrBinary = errors.New("binary file")
+
+// stdinName names standard input in notices and errors, as grep does.
+const stdinName = "(standard input)"
+
+// isBinary reports whether a sample of an input looks binary: it holds a NUL
+// byte, or more than 30% of it is invalid UTF-8 or control characters other
+// than whitespace and escape.
+func isBinary(sample []byte) bool {
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	suspicious := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			suspicious++
+		case r == 0x7f, r < 0x20 && !strings.ContainsRune("\t\n\v\f\r\b\x1b", r):
+			suspicious++
+		}
+		i += size
+	}
+	return suspicious*10 > len(sample)*3
+}
+
+// sampleFile returns the first and last blocks of a file.
+func sampleFile(r io.ReaderAt, size int64) ([]byte, error) {
+	if size <= 2*blockSize {
+		sample := make([]byte, size)
+		_, err := r.ReadAt(sample, 0)
+		if err == io.EOF {
+			err = nil
+		}
+		return sample, err
+	}
+	sample := make([]byte, 2*blockSize)
+	if _, err := r.ReadAt(sample[:blockSize], 0); err != nil {
+		return nil, err
+	}
+	if _, err := r.ReadAt(sample[blockSize:], size-blockSize); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return sample, nil
+}
+
+// sampleLines returns the first and last lines of an input read whole, up to
+// a block of each.
+func sampleLines(lines []string) []byte {
+	var head, tail []byte
+	for _, line := range lines {
+		if len(head) >= blockSize {
+			break
+		}
+		head = append(append(head, line...), '\n')
+	}
+	for i := len(lines) - 1; i >= 0 && len(tail) < blockSize && len(head)+len(tail) < len(lines); i-- {
+		tail = append([]byte(lines[i]+"\n"), tail...)
+	}
+	return append(head, tail...)
+}
+
+// inspect decides how to read an input from a sample of its content. It
+// returns the decoder for the input's lines, or false when the input is not
+// to be shown, in which case it has already written a notice for it.
+func (e *engine) inspect(stdout io.Writer, name string, sample []byte) (lineDecoder, bool, error) {
+	decoder := e.readDecoder
+	if e.Encoding == EncodingLatin1 {
+		// Every byte is a character, so judge the text it becomes
+		sample = []byte(latin1ToUTF8(string(sample)))
+	}
+	if e.Binary == BinaryRaw || !isBinary(sample) {
+		return decoder, true, nil
+	}
+	switch e.Binary {
+	case BinaryRefuse:
+		return decoder, false, fmt.Errorf("%s: %w", name, ErrBinary)
+	case BinaryNotice:
+		_, err := fmt.Fprintf(stdout, "Binary file %s matches\n", name)
+		return decoder, false, err
+	case BinaryCaret:
+		decoder.escape = escapeCaret
+	default:
+		decoder.escape = escapeHex
+	}
+	return decoder, true, nil
+}
+
+// inspectFile inspects a regular file by its first and last blocks.
+func (e *engine) inspectFile(stdout io.Writer, f *os.File) (lineDecoder, bool, error) {
+	if e.Binary == BinaryRaw {
+		return e.readDecoder, true, nil
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return lineDecoder{}, false, err
+	}
+	sample, err := sampleFile(f, info.Size())
+	if err != nil {
+		return lineDecoder{}, false, err
+	}
+	return e.inspect(stdout, f.Name(), sample)
+}
+
+// escapeLines escapes lines that were read before their input was
+// inspected.
+func (d lineDecoder) escapeLines(lines []string) []string {
+	if d.escape != nil {
+		for i, line := range lines {
+			lines[i] = d.escape(line)
+		}
+	}
+	return lines
+}
+
+// escapeCaret shows control characters as ^X and bytes above 0x7f as M-
+// followed by the low seven bits, as cat -v does. Tabs are kept.
+func escapeCaret(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c >= 0x80 {
+			b.WriteString("M-")
+			c -= 0x80
+		}
+		switch {
+		case c == '\t' && line[i] == '\t':
+			b.WriteByte(c)
+		case c < 0x20:
+			b.WriteByte('^')
+			b.WriteByte(c + '@')
+		case c == 0x7f:
+			b.WriteString("^?")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// escapeHex shows invalid UTF-8 and non-printable characters as \xNN,
+// keeping printable characters and tabs.
+func escapeHex(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if (r == utf8.RuneError && size == 1) || (r != '\t' && !unicode.IsPrint(r)) {
+			for _, c := range []byte(line[i : i+size]) {
+				fmt.Fprintf(&b, `\x%02x`, c)
+			}
+		} else {
+			b.WriteString(line[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+const binaryContent = "ELF\x00\x01\x02\x7f\xfe\n\x1b[31mred\x1b[0m\tend\n"
+
+// ==============================================================================
+// Test Binary Detection
+// ==============================================================================
+
+func TestTail_BinaryRawByDefault(t *testing.T) {
+	path := writeFile(t, binaryContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(path)), binaryContent, "output")
+}
+
+func TestTail_BinaryRefuse(t *testing.T) {
+	path := writeFile(t, binaryContent)
+
+	var out bytes.Buffer
+	err := command.Tail(path, command.BinaryRefuse).Executor()(context.Background(), nil, &out, io.Discard)
+
+	assertion.Equal(t, errors.Is(err, command.ErrBinary), true, "errors.Is ErrBinary")
+	assertion.ErrorContains(t, err, path+": binary file")
+	assertion.Equal(t, out.String(), "", "output")
+}
+
+func TestTail_BinaryNotice(t *testing.T) {
+	text := writeFile(t, "a\nb\n")
+	binary := writeFile(t, binaryContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(text, binary, command.Quiet, command.BinaryNotice)),
+		"a\nb\nBinary file "+binary+" matches\n", "output")
+}
+
+func TestTail_BinaryNoticeBetweenFiles(t *testing.T) {
+	// The notice stays at the binary file's place among the records shown
+	first := writeFile(t, "a\nb\n")
+	binary := writeFile(t, binaryContent)
+	last := writeFile(t, "c\nd")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, binary, last, command.Quiet, command.LineCount(3), command.BinaryNotice)),
+		"b\nBinary file "+binary+" matches\nc\nd", "output")
+	assertion.Equal(t, runFile(t, command.Tail(first, binary, last, command.Quiet, command.LineCount(2), command.BinaryNotice)),
+		"Binary file "+binary+" matches\nc\nd", "output")
+}
+
+func TestTail_BinaryCaret(t *testing.T) {
+	path := writeFile(t, binaryContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.BinaryCaret)),
+		"ELF^@^A^B^?M-~\n^[[31mred^[[0m\tend\n", "output")
+}
+
+func TestTail_BinaryHex(t *testing.T) {
+	path := writeFile(t, binaryContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.BinaryHex)),
+		`ELF\x00\x01\x02\x7f\xfe`+"\n"+`\x1b[31mred\x1b[0m`+"\tend\n", "output")
+}
+
+func TestTail_BinaryEscapedBeforeTruncation(t *testing.T) {
+	// The escaped line is what MaxLineLength caps
+	path := writeFile(t, "\x00\x01\x02\x03\x04\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.BinaryHex, command.MaxLineLength(8))),
+		`\x00\x01`+"…[+12 bytes]\n", "output")
+}
+
+func TestTail_BinaryTextFileUntouched(t *testing.T) {
+	content := "plain\n\x1b[32mgreen\x1b[0m\n日本語\n"
+	path := writeFile(t, content)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.BinaryHex)), content, "output")
+}
+
+func TestTail_BinaryMostlyInvalidUTF8(t *testing.T) {
+	path := writeFile(t, strings.Repeat("\xff\xfeab", 100)+"\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.BinaryNotice)),
+		"Binary file "+path+" matches\n", "output")
+}
+
+func TestTail_BinaryFewInvalidBytesIsText(t *testing.T) {
+	// A Latin-1 accent in otherwise plain text is not binary
+	content := "caf\xe9 au lait\n"
+	path := writeFile(t, content)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.BinaryNotice)), content, "output")
+}
+
+func TestTail_BinaryDetectedAtEndOfLargeFile(t *testing.T) {
+	path := writeFile(t, numbered("text", 20000)+"\x00\x00\x00\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(1), command.BinaryHex)),
+		`\x00\x00\x00`+"\n", "output")
+}
+
+func TestTail_BinaryStdin(t *testing.T) {
+	result := run.Command(command.Tail(command.BinaryNotice)).
+		WithStdinLines("\x00\x01", "data").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"Binary file (standard input) matches"})
+}
+
+func TestTail_BinaryStdinEscaped(t *testing.T) {
+	result := run.Command(command.Tail(command.BinaryCaret)).
+		WithStdinLines("\x00\x01", "data").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"^@^A", "data"})
+}
+
+func TestTail_BinaryFollowEscapesNewLines(t *testing.T) {
+	path := writeFile(t, "\x00\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.BinaryHex, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "\x01x\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{`\x00`, `\x01x`})
+}
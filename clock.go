@@ -0,0 +1,16 @@
+package command
+
+import "time"
+
+// Clock is the time source used by follow mode. Timestamps are taken from Now
+// and the wait between polls for new data comes from After, so a fake clock
+// makes follow mode deterministic.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,196 @@
+package command
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const colorReset = "\x1b[0m"
+
+// labelPalette colors pod labels and file headers in turn.
+var labelPalette = []Color{ColorCyan, ColorMagenta, ColorBlue, ColorGreen, "96", "95", "94", "92"}
+
+// levels are the level names levelKeyword finds, in any case.
+const levels = `(?i:trace|debug|info|notice|warn|warning|error|err|crit|critical|fatal|alert|panic|emerg)`
+
+// levelKeyword finds the log level of a plain text line where it is a level
+// token: first on the line, after a timestamp if any, as the "ERROR" of
+// "2026-10-17 10:00:00 ERROR db down", in brackets, or in a level= field.
+// The same word elsewhere, as in "no error", is not a level.
+var levelKeyword = regexp.MustCompile(`^[\d\s:.,/TZ+-]*(` + levels + `)\b|\[(` + levels + `)\]|\b(?:level|lvl|severity)=["']?(` + levels + `)\b`)
+
+// levelFields are the fields a parsed record's level is looked up in.
+var levelFields = []string{"level", "lvl", "severity", "log.level"}
+
+// highlight is a compiled Highlight.
+type highlight struct {
+	re    *regexp.Regexp
+	color Color
+}
+
+func compileHighlights(highlights []Highlight) ([]highlight, error) {
+	compiled := make([]highlight, len(highlights))
+	for i, h := range highlights {
+		re, err := regexp.Compile(h.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = highlight{re: re, color: h.Color}
+	}
+	return compiled, nil
+}
+
+// setColor decides whether output to stdout is colored. ColorAuto colors
+// a terminal unless NO_COLOR is set to anything but "".
+func (e *engine) setColor(stdout io.Writer) {
+	switch e.Color {
+	case ColorAlways:
+		e.color = true
+	case ColorNever:
+		e.color = false
+	default:
+		e.color = os.Getenv("NO_COLOR") == "" && isTerminal(stdout)
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// levelColor returns the color of a record's level: red for errors and
+// worse, yellow for warnings and dim for debug and trace. The level is taken
+// from the record's fields in the configured Format, or from a JSON or
+// logfmt record's level field, or else from the first level keyword on its
+// first line.
+func (e *engine) levelColor(record string) Color {
+	rank, ok := e.recordLevel(record)
+	switch {
+	case !ok:
+		return ""
+	case rank >= levelRanks["error"]:
+		return ColorRed
+	case rank >= levelRanks["warn"]:
+		return ColorYellow
+	case rank <= levelRanks["debug"]:
+		return ColorDim
+	default:
+		return ""
+	}
+}
+
+func (e *engine) recordLevel(record string) (int, bool) {
+	f, ok := e.parseFields(record)
+	if !ok {
+		if f, ok = parseJSON(record); !ok {
+			f, ok = parseLogfmt(record)
+		}
+	}
+	if ok {
+		for _, name := range levelFields {
+			if v, found := f.lookup(name); found {
+				return levelRank(fieldString(v))
+			}
+		}
+	}
+	first, _, _ := strings.Cut(record, "\n")
+	if m := levelKeyword.FindStringSubmatch(first); m != nil {
+		for _, level := range m[1:] {
+			if level != "" {
+				return levelRank(level)
+			}
+		}
+	}
+	return 0, false
+}
+
+// colorize colors each line of a rendered record in the record's level
+// color, and the Highlight matches in theirs. When matches overlap, the
+// one that starts first wins, then the earlier Highlight.
+func (e *engine) colorize(record, line string) string {
+	base := e.levelColor(record)
+	if base == "" && len(e.highlights) == 0 {
+		return line
+	}
+	lines := strings.Split(line, "\n")
+	for i, l := range lines {
+		lines[i] = e.colorizeLine(base, l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// span is a Highlight match in a line.
+type span struct {
+	start, end int
+	color      Color
+}
+
+func (e *engine) colorizeLine(base Color, line string) string {
+	var spans []span
+	for _, h := range e.highlights {
+		for _, m := range h.re.FindAllStringIndex(line, -1) {
+			if m[1] > m[0] {
+				spans = append(spans, span{m[0], m[1], h.color})
+			}
+		}
+	}
+	if base == "" && len(spans) == 0 {
+		return line
+	}
+	slices.SortStableFunc(spans, func(a, b span) int { return a.start - b.start })
+
+	var b strings.Builder
+	plain := func(text string) {
+		if text != "" && base != "" {
+			text = sgr(base) + text + colorReset
+		}
+		b.WriteString(text)
+	}
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		plain(line[last:s.start])
+		b.WriteString(sgr(s.color) + line[s.start:s.end] + colorReset)
+		last = s.end
+	}
+	plain(line[last:])
+	return b.String()
+}
+
+// sgr returns the escape sequence that turns a color on, or "" for none.
+func sgr(c Color) string {
+	if c == "" {
+		return ""
+	}
+	return "\x1b[" + string(c) + "m"
+}
+
+// labelPrefix returns the "[LABEL] " prefix of a pod label, in a color of
+// its own when output is colored.
+func (e *engine) labelPrefix(label string) string {
+	prefix := "[" + label + "]"
+	if !e.color {
+		return prefix + " "
+	}
+	return sgr(e.labelColor(label)) + prefix + colorReset + " "
+}
+
+// labelColor returns the color of a pod label or file name, the next color
+// of the palette for each new one.
+func (e *engine) labelColor(label string) Color {
+	c, ok := e.labelColors[label]
+	if !ok {
+		c = labelPalette[len(e.labelColors)%len(labelPalette)]
+		e.labelColors[label] = c
+	}
+	return c
+}
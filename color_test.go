@@ -0,0 +1,136 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+const (
+	red    = "\x1b[31m"
+	yellow = "\x1b[33m"
+	dim    = "\x1b[2m"
+	reset  = "\x1b[0m"
+)
+
+// ==============================================================================
+// Test Colored Output
+// ==============================================================================
+
+func TestTail_ColorPlainTextLevels(t *testing.T) {
+	result := run.Command(command.Tail(command.ColorAlways)).
+		WithStdinLines(
+			"10:00:00 ERROR db down",
+			"10:00:01 [warn] disk at 91%",
+			"10:00:02 DEBUG cache miss",
+			"10:00:03 INFO started",
+			"no level here",
+			"2026-10-17T10:00:04Z retrying [ERR] after timeout",
+			"10:00:05 exited with no error",
+			`10:00:06 "nightly" job level=warn`,
+		).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		red + "10:00:00 ERROR db down" + reset,
+		yellow + "10:00:01 [warn] disk at 91%" + reset,
+		dim + "10:00:02 DEBUG cache miss" + reset,
+		"10:00:03 INFO started",
+		"no level here",
+		red + "2026-10-17T10:00:04Z retrying [ERR] after timeout" + reset,
+		"10:00:05 exited with no error",
+		yellow + `10:00:06 "nightly" job level=warn` + reset,
+	})
+}
+
+func TestTail_ColorStructuredLevels(t *testing.T) {
+	result := run.Command(command.Tail(command.ColorAlways)).
+		WithStdinLines(
+			`{"level":"fatal","msg":"info about an error"}`,
+			`level=warn msg="debug endpoint slow"`,
+			`{"log":{"level":"debug"},"msg":"ERROR in text only"}`,
+		).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		red + `{"level":"fatal","msg":"info about an error"}` + reset,
+		yellow + `level=warn msg="debug endpoint slow"` + reset,
+		dim + `{"log":{"level":"debug"},"msg":"ERROR in text only"}` + reset,
+	})
+}
+
+func TestTail_ColorSyslogSeverity(t *testing.T) {
+	result := run.Command(command.Tail(command.ColorAlways, command.FormatSyslog, command.Template("{{.msg}}"))).
+		WithStdinLines(syslogLines[1], syslogLines[0]).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{red + "upstream timed out" + reset, "Accepted publickey for deploy"})
+}
+
+func TestTail_ColorHighlight(t *testing.T) {
+	result := run.Command(command.Tail(command.ColorAlways,
+		command.Highlight{Pattern: `user=\w+`, Color: command.ColorCyan},
+		command.Highlight{Pattern: `\d+ms`, Color: "1;35"},
+		command.Highlight{Pattern: `user`, Color: command.ColorGreen})).
+		WithStdinLines("ERROR user=bob took 250ms", "INFO user=amy").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		red + "ERROR " + reset + "\x1b[36muser=bob" + reset + red + " took " + reset + "\x1b[1;35m250ms" + reset,
+		"INFO \x1b[36muser=amy" + reset,
+	})
+}
+
+func TestTail_ColorMultilineRecord(t *testing.T) {
+	result := run.Command(command.Tail(command.ColorAlways, command.StackTraceContinuation, command.LineCount(1))).
+		WithStdinLines("ERROR boom", "\tat main.go:10").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{red + "ERROR boom" + reset, red + "\tat main.go:10" + reset})
+}
+
+func TestTail_ColorAutoOffForNonTerminal(t *testing.T) {
+	result := run.Command(command.Tail(command.Highlight{Pattern: "db", Color: command.ColorBold})).
+		WithStdinLines("ERROR db down").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"ERROR db down"})
+}
+
+func TestTail_ColorAlwaysIgnoresNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	result := run.Command(command.Tail(command.ColorAlways)).
+		WithStdinLines("ERROR db down").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{red + "ERROR db down" + reset})
+}
+
+func TestTail_ColorPodLabels(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("default/api-7d9f"), command.PodLogDir(root), command.LineCount(1),
+		command.ColorAlways))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"\x1b[36m[default/api-7d9f/app]" + reset + " second run 2",
+		"\x1b[35m[default/api-7d9f/proxy]" + reset + " proxy up",
+	})
+}
+
+func TestTail_HighlightInvalid(t *testing.T) {
+	result := run.Quick(command.Tail(command.Highlight{Pattern: "(", Color: command.ColorRed}))
+
+	assertion.ErrorContains(t, result.Err, "missing closing )")
+}
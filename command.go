@@ -1,6 +1,13 @@
 package command
 
 import (
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
 	gloo "github.com/gloo-foo/framework"
 )
 
@@ -8,25 +15,146 @@ type command gloo.Inputs[gloo.File, flags]
 
 func Tail(parameters ...any) gloo.Command {
 	cmd := command(gloo.Initialize[gloo.File, flags](parameters...))
-	if cmd.Flags.Lines == 0 && cmd.Flags.Bytes == 0 {
+	if cmd.Flags.Lines == 0 && cmd.Flags.Bytes == 0 && cmd.Flags.Since == 0 && time.Time(cmd.Flags.SinceTime).IsZero() {
 		cmd.Flags.Lines = 10
 	}
 	return cmd
 }
 
 func (p command) Executor() gloo.CommandExecutor {
-	lineCount := int(p.Flags.Lines)
-	if lineCount == 0 {
-		lineCount = 10
+	e, err := newEngine(p)
+	if err != nil {
+		return gloo.RawCommand(func(context.Context, io.Reader, io.Writer, io.Writer) error {
+			return err
+		}).Executor()
+	}
+	return p.executor(e)
+}
+
+// executor runs the command with its engine. WaitFor implies Follow.
+func (p command) executor(e *engine) gloo.CommandExecutor {
+	follow := bool(p.Flags.Follow) || e.wait != nil
+
+	if len(e.pods) > 0 {
+		e.following = follow
+		return gloo.RawCommand(func(ctx context.Context, _ io.Reader, stdout, stderr io.Writer) error {
+			e.setOutput(stdout, stderr)
+			if follow {
+				return e.followPods(ctx, stdout)
+			}
+			return e.tailPods(stdout)
+		}).Executor()
 	}
 
-	return gloo.Inputs[gloo.File, flags](p).Wrap(
-		gloo.AccumulateAndProcess(func(lines []string) []string {
-			// Return last N lines
-			if len(lines) <= lineCount {
-				return lines
+	// UTF-16 files can't be read from the end, so they are read like stdin
+	// unless they are followed
+	if files, ok := p.regularFiles(); ok && (follow || !e.utf16Input()) {
+		if follow {
+			e.following = true
+			return gloo.RawCommand(func(ctx context.Context, _ io.Reader, stdout, stderr io.Writer) error {
+				e.setOutput(stdout, stderr)
+				return e.follow(ctx, files, stdout)
+			}).Executor()
+		}
+		return gloo.RawCommand(func(_ context.Context, _ io.Reader, stdout, stderr io.Writer) error {
+			e.setOutput(stdout, stderr)
+			if e.showHeaders(len(files)) {
+				return e.tailSections(files, stdout)
 			}
-			return lines[len(lines)-lineCount:]
-		}).Executor(),
-	)
+			return e.tailFiles(files, stdout)
+		}).Executor()
+	}
+
+	// Followed streams are read as one, without headers
+	inputs := gloo.Inputs[gloo.File, flags](p)
+	return gloo.RawCommand(func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+		e.setOutput(stdout, stderr)
+		if follow {
+			return e.followStream(ctx, stdout, inputs.Reader(stdin))
+		}
+		readers := inputs.Readers()
+		if e.showHeaders(max(len(readers), 1)) {
+			if len(readers) == 0 {
+				readers = []io.Reader{stdin}
+			}
+			return e.tailStreamSections(readers, stdout)
+		}
+		return e.tailStream(stdout, stdinName, inputs.Reader(stdin))
+	}).Executor()
+}
+
+// regularFiles returns the inputs when they are all regular files, which can
+// be read from the end and followed. Stdin and pipes are read to EOF instead.
+func (p command) regularFiles() ([]*os.File, bool) {
+	readers := gloo.Inputs[gloo.File, flags](p).Readers()
+	if len(readers) == 0 {
+		return nil, false
+	}
+	files := make([]*os.File, 0, len(readers))
+	for _, r := range readers {
+		f, ok := r.(*os.File)
+		if !ok {
+			return nil, false
+		}
+		info, err := f.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil, false
+		}
+		files = append(files, f)
+	}
+	return files, true
+}
+
+// tailFiles writes the last records of files, reading them from the end, or
+// from the start of the time window when one is set. Binary files are
+// inspected before anything is written, and the notice for one that isn't
+// shown is written at its place among the records of the others.
+func (e *engine) tailFiles(files []*os.File, stdout io.Writer) error {
+	var inputs []*backwardReader
+	notices := []string{""} // before each input, and after the last
+	for _, f := range files {
+		var notice strings.Builder
+		decoder, show, err := e.inspectFile(&notice, f)
+		if err != nil {
+			return err
+		}
+		notices[len(inputs)] += notice.String()
+		if !show {
+			continue
+		}
+		b, err := e.openBackward(f, decoder)
+		if err != nil {
+			return err
+		}
+		inputs, notices = append(inputs, b), append(notices, "")
+	}
+
+	var records []string
+	var from []int
+	partial := false
+	if e.sinceActive() {
+		for i, b := range inputs {
+			since, p, err := e.readSince(b.r, b.size, b.decoder)
+			if err != nil {
+				return err
+			}
+			if len(since) > 0 {
+				records, partial = append(records, since...), p
+				from = append(from, slices.Repeat([]int{i}, len(since))...)
+			}
+		}
+		records, from = lastLines(records, e.lineCount()), lastLines(from, e.lineCount())
+	} else {
+		var err error
+		if records, from, partial, err = e.scanLast(inputs); err != nil {
+			return err
+		}
+	}
+	// Each notice goes before the first record of the inputs after it
+	placed := map[int]string{}
+	for input, notice := range notices {
+		at, _ := slices.BinarySearch(from, input)
+		placed[at] += notice
+	}
+	return e.writeNoticed(stdout, records, placed, partial)
 }
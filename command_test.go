@@ -696,3 +696,348 @@ func TestTail_TwoLinesCustomCount(t *testing.T) {
 	assertion.Lines(t, result.Stdout, []string{"second"})
 }
 
+// ==============================================================================
+// Test Multiline Records
+// ==============================================================================
+
+func TestTail_ContinuationGroupsStackTrace(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.StackTraceContinuation)).
+		WithStdinLines(
+			"INFO starting",
+			"ERROR request failed",
+			"java.lang.IllegalStateException: boom",
+			"\tat com.example.Handler.run(Handler.java:42)",
+			"Caused by: java.io.IOException: closed",
+			"\tat com.example.Conn.read(Conn.java:7)",
+			"\t... 3 more",
+			"INFO recovered",
+		).Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"java.lang.IllegalStateException: boom",
+		"\tat com.example.Handler.run(Handler.java:42)",
+		"Caused by: java.io.IOException: closed",
+		"\tat com.example.Conn.read(Conn.java:7)",
+		"\t... 3 more",
+		"INFO recovered",
+	})
+}
+
+func TestTail_ContinuationCountsRecords(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.IndentedContinuation)).
+		WithStdinLines("first", "  a", "second", "  b", "  c").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"second", "  b", "  c"})
+}
+
+func TestTail_ContinuationLeadingOrphanLines(t *testing.T) {
+	// Continuation lines before any start line form their own record
+	result := run.Command(command.Tail(command.LineCount(2), command.IndentedContinuation)).
+		WithStdinLines("  orphan", "  more", "start").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"  orphan", "  more", "start"})
+}
+
+func TestTail_ContinuationCustomPattern(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.ContinuationPattern(`^\+`))).
+		WithStdinLines("a", "+1", "b", "+2", "+3").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"b", "+2", "+3"})
+}
+
+func TestTail_ContinuationInvalidPattern(t *testing.T) {
+	result := run.Command(command.Tail(command.ContinuationPattern(`(`))).
+		WithStdinLines("a").
+		Run()
+
+	assertion.ErrorContains(t, result.Err, "missing closing )")
+}
+
+// ==============================================================================
+// Test Include and Exclude Patterns
+// ==============================================================================
+
+func TestTail_IncludeCountsMatches(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.IncludePattern("ERROR"))).
+		WithStdinLines("ERROR a", "INFO b", "ERROR c", "INFO d", "ERROR e", "INFO f").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"ERROR c", "ERROR e"})
+}
+
+func TestTail_ExcludePattern(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.ExcludePattern("^DEBUG"))).
+		WithStdinLines("INFO a", "INFO b", "DEBUG c", "DEBUG d").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"INFO a", "INFO b"})
+}
+
+func TestTail_IncludeAnyOfSeveral(t *testing.T) {
+	result := run.Command(command.Tail(
+		command.IncludePattern("ERROR"),
+		command.IncludePattern("WARN"),
+		command.ExcludePattern("ignored"),
+	)).
+		WithStdinLines("ERROR a", "INFO b", "WARN c", "ERROR ignored d").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"ERROR a", "WARN c"})
+}
+
+func TestTail_IncludeMatchesWholeRecord(t *testing.T) {
+	result := run.Command(command.Tail(command.IndentedContinuation, command.IncludePattern("NullPointer"))).
+		WithStdinLines("INFO ok", "ERROR failed", "  java.lang.NullPointerException", "INFO ok").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"ERROR failed", "  java.lang.NullPointerException"})
+}
+
+func TestTail_IncludeInvalidPattern(t *testing.T) {
+	result := run.Command(command.Tail(command.IncludePattern("["))).
+		WithStdinLines("a").
+		Run()
+
+	assertion.ErrorContains(t, result.Err, "missing closing ]")
+}
+
+// ==============================================================================
+// Test Output Byte Budget
+// ==============================================================================
+
+func TestTail_MaxOutputBytesDropsEarliest(t *testing.T) {
+	// The marker's 38 bytes count against the budget
+	result := run.Command(command.Tail(command.LineCount(3), command.MaxOutputBytes(48))).
+		WithStdinLines("aaaa", strings.Repeat("b", 40), "cccc", "dddd").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"[... 1 line (41 bytes) truncated ...]",
+		"cccc",
+		"dddd",
+	})
+}
+
+func TestTail_MaxOutputBytesFits(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.MaxOutputBytes(4))).
+		WithStdinLines("a", "b", "c").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"b", "c"})
+}
+
+func TestTail_MaxOutputBytesStopsAtFirstOverflow(t *testing.T) {
+	// A short line before an oversized one is dropped as well
+	result := run.Command(command.Tail(command.LineCount(3), command.MaxOutputBytes(41))).
+		WithStdinLines("a", strings.Repeat("x", 50), "b").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[... 2 lines (53 bytes) truncated ...]", "b"})
+}
+
+func TestTail_MaxOutputBytesLastLineTooLarge(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.MaxOutputBytes(40))).
+		WithStdinLines(strings.Repeat("x", 1000)).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[... 1 line (1001 bytes) truncated ...]"})
+}
+
+func TestTail_MaxOutputBytesMarkerTooLarge(t *testing.T) {
+	// The latest lines that fit are written without the marker
+	result := run.Command(command.Tail(command.LineCount(6), command.MaxOutputBytes(30))).
+		WithStdinLines("line one 1", "line two 2", "line thr 3", "line fou 4", "line fiv 5", "line six 6").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"line fiv 5", "line six 6"})
+}
+
+func TestTail_MaxOutputBytesNothingFits(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.MaxOutputBytes(10))).
+		WithStdinLines(strings.Repeat("x", 1000)).
+		Run()
+
+	assertion.ErrorContains(t, result.Err, "MaxOutputBytes has no room")
+	assertion.Lines(t, result.Stdout, nil)
+}
+
+func TestTail_MaxOutputBytesCountsRecordLines(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.IndentedContinuation, command.MaxOutputBytes(44))).
+		WithStdinLines("start", "  "+strings.Repeat("a", 30), "  b", "next").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[... 3 lines (43 bytes) truncated ...]", "next"})
+}
+
+func TestTail_MaxOutputBytesAfterRendering(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.FormatJSON,
+		command.Field("msg"), command.MaxOutputBytes(30))).
+		WithStdinLines(`{"msg":"a","payload":"`+strings.Repeat("x", 100)+`"}`, `{"msg":"b"}`).
+		Run()
+
+	// The budget applies to the rendered lines, not the raw records
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{`{"msg":"a"}`, `{"msg":"b"}`})
+}
+
+// ==============================================================================
+// Test Line Length Cap
+// ==============================================================================
+
+func TestTail_MaxLineLengthTruncates(t *testing.T) {
+	result := run.Command(command.Tail(command.MaxLineLength(5))).
+		WithStdinLines("short", "exactly5", strings.Repeat("a", 10000)).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"short",
+		"exact…[+3 bytes]",
+		"aaaaa…[+9995 bytes]",
+	})
+}
+
+func TestTail_MaxLineLengthKeepsRunesWhole(t *testing.T) {
+	// Each of these characters is three bytes long
+	result := run.Command(command.Tail(command.MaxLineLength(7))).
+		WithStdinLines("日本語です").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"日本…[+9 bytes]"})
+}
+
+func TestTail_MaxLineLengthCustomMarker(t *testing.T) {
+	result := run.Command(command.Tail(command.MaxLineLength(3), command.TruncationMarker(" (%d more)"))).
+		WithStdinLines("abcdef").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"abc (3 more)"})
+}
+
+func TestTail_MaxLineLengthMarkerWithoutCount(t *testing.T) {
+	result := run.Command(command.Tail(command.MaxLineLength(3), command.TruncationMarker("…"))).
+		WithStdinLines("abcdefghij").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"abc…"})
+}
+
+func TestTail_MaxLineLengthMarkerPercent(t *testing.T) {
+	result := run.Command(command.Tail(command.MaxLineLength(3), command.TruncationMarker(" [%d bytes, 100%]"))).
+		WithStdinLines("abcdef").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"abc [3 bytes, 100%]"})
+}
+
+func TestTail_MaxLineLengthIgnoresStrippedCarriageReturn(t *testing.T) {
+	result := run.Command(command.Tail(command.MaxLineLength(3), command.CRLFStrip)).
+		WithStdinLines("abc\r", "abcd\r").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"abc", "abc…[+1 bytes]"})
+}
+
+func TestTail_MaxLineLengthFiltersWholeLines(t *testing.T) {
+	// The cap only shortens what is written, never what is selected
+	result := run.Command(command.Tail(command.MaxLineLength(10), command.IncludePattern("ERROR"))).
+		WithStdinLines("2026-10-17 ERROR failed", "2026-10-17 INFO ok").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"2026-10-17…[+13 bytes]"})
+}
+
+func TestTail_MaxLineLengthMarkerNotFiltered(t *testing.T) {
+	result := run.Command(command.Tail(command.MaxLineLength(3), command.ExcludePattern("bytes"))).
+		WithStdinLines("abcdef").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"abc…[+3 bytes]"})
+}
+
+func TestTail_MaxLineLengthEachRecordLine(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.IndentedContinuation, command.MaxLineLength(4))).
+		WithStdinLines("start of record", "  continued line").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"star…[+11 bytes]", "  co…[+12 bytes]"})
+}
+
+func TestTail_MaxLineLengthAfterRendering(t *testing.T) {
+	// Parsed records are read whole and truncated once rendered
+	result := run.Command(command.Tail(command.FormatJSON, command.Template("{{.msg}}"), command.MaxLineLength(4))).
+		WithStdinLines(`{"msg":"a long message"}`).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"a lo…[+10 bytes]"})
+}
+
+func TestTail_MaxLineLengthBeyondReadLimit(t *testing.T) {
+	// Lines this long are cut as they are read rather than held whole
+	long := strings.Repeat("z", 200000)
+	result := run.Command(command.Tail(command.LineCount(2), command.MaxLineLength(5))).
+		WithStdinLines(long, "end").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"zzzzz…[+199995 bytes]", "end"})
+
+	path := writeFile(t, "start\n"+long+"\nend\n")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2), command.MaxLineLength(5))),
+		"zzzzz…[+199995 bytes]\nend\n", "output")
+}
+
+func TestTail_MaxLineLengthBeyondReadLimitCarriageReturn(t *testing.T) {
+	path := writeFile(t, strings.Repeat("z", 200000)+"\r\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.MaxLineLength(5), command.CRLFPreserve)),
+		"zzzzz…[+199995 bytes]\r\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.MaxLineLength(5), command.CRLFStrip)),
+		"zzzzz…[+199995 bytes]\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.MaxLineLength(5))),
+		"zzzzz…[+199996 bytes]\n", "output")
+}
+
+func TestTail_MaxLineLengthBeyondReadLimitCustomMarker(t *testing.T) {
+	// A line truncated as it is read is not truncated again on output
+	path := writeFile(t, strings.Repeat("日", 100000)+"\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.MaxLineLength(7), command.TruncationMarker(" (%d more)"))),
+		"日日 (299994 more)\n", "output")
+}
+
+func TestTail_LineLongerThanScannerLimit(t *testing.T) {
+	longLine := strings.Repeat("z", 200000)
+	result := run.Command(command.Tail(command.LineCount(1))).
+		WithStdinLines("short", longLine).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{longLine})
+}
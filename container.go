@@ -0,0 +1,218 @@
+package command
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// containerEntry is a line of a container runtime's log: what the container
+// wrote to one of its streams, or a fragment of it when the runtime split a
+// long line into partial entries.
+type containerEntry struct {
+	stream  string
+	message string
+	partial bool   // whether the line continues in the stream's next entry
+	stamp   string // when the runtime read it, in RFC 3339, if it says
+}
+
+// parseContainer parses a line in the configured ContainerFormat.
+func (e *engine) parseContainer(line string) (containerEntry, bool) {
+	switch e.Container {
+	case ContainerDocker:
+		return parseDocker(line)
+	case ContainerCRI:
+		return parseCRI(line)
+	default:
+		return containerEntry{}, false
+	}
+}
+
+// parseDocker parses a line of Docker's json-file log, such as
+// {"log":"started\n","stream":"stdout","time":"2026-10-17T10:00:00Z"}. A log
+// without its newline is continued by the next entry.
+func parseDocker(line string) (containerEntry, bool) {
+	var entry struct {
+		Log    *string `json:"log"`
+		Stream string  `json:"stream"`
+		Time   string  `json:"time"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Log == nil {
+		return containerEntry{}, false
+	}
+	message, complete := strings.CutSuffix(*entry.Log, "\n")
+	return containerEntry{stream: entry.Stream, message: message, partial: !complete, stamp: entry.Time}, true
+}
+
+// parseCRI parses a line of a CRI runtime's log, such as
+// "2026-10-17T10:00:00.000000000Z stdout F started". The tag is P for a
+// partial entry and F for the last entry of a line, optionally followed by
+// more tags after a colon.
+func parseCRI(line string) (containerEntry, bool) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 || parts[1] != "stdout" && parts[1] != "stderr" {
+		return containerEntry{}, false
+	}
+	tag, _, _ := strings.Cut(parts[2], ":")
+	if tag != "P" && tag != "F" {
+		return containerEntry{}, false
+	}
+	entry := containerEntry{stream: parts[1], partial: tag == "P", stamp: parts[0]}
+	if len(parts) == 4 {
+		entry.message = parts[3]
+	}
+	return entry, true
+}
+
+// time returns when the runtime read an entry, if it says.
+func (c containerEntry) time() (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, c.stamp)
+	return t, err == nil
+}
+
+// containerJoiner unwraps the entries of a container runtime's log into the
+// lines the container wrote, joining the fragments of each stream's partial
+// entries. Entries of a stream other than ContainerStream are dropped, and
+// lines not in the format pass through unchanged.
+type containerJoiner struct {
+	engine    *engine
+	fragments map[string][]string // by stream, until the entry ending their line
+}
+
+// newJoiner returns a joiner for the ContainerFormat, or nil without one.
+func (e *engine) newJoiner() *containerJoiner {
+	if e.Container == ContainerNone {
+		return nil
+	}
+	return &containerJoiner{engine: e, fragments: map[string][]string{}}
+}
+
+// add returns the lines completed by the entries read.
+func (j *containerJoiner) add(entries []string) []string {
+	var lines []string
+	for _, line := range entries {
+		entry, ok := j.engine.parseContainer(line)
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		if !j.engine.streamShown(entry.stream) {
+			continue
+		}
+		fragments := append(j.fragments[entry.stream], entry.message)
+		if entry.partial {
+			j.fragments[entry.stream] = fragments
+			continue
+		}
+		delete(j.fragments, entry.stream)
+		lines = append(lines, strings.Join(fragments, ""))
+	}
+	return lines
+}
+
+// pending reports whether fragments are waiting for the entry ending their
+// line.
+func (j *containerJoiner) pending() bool {
+	return j != nil && len(j.fragments) > 0
+}
+
+// flush returns the lines whose last entry has not been read, as they are.
+func (j *containerJoiner) flush() []string {
+	var lines []string
+	for _, stream := range slices.Sorted(maps.Keys(j.fragments)) {
+		lines = append(lines, strings.Join(j.fragments[stream], ""))
+	}
+	clear(j.fragments)
+	return lines
+}
+
+// maxInterleaved is how many entries of other streams reverseJoiner reads
+// past a line before it takes the line to be whole. Runtimes write the
+// entries of a split line together, so only a few others come between them.
+const maxInterleaved = 64
+
+// reverseJoiner is the containerJoiner for entries read from last to first.
+// A line is whole once the entry before it in its stream has been read, or
+// maxInterleaved entries of other streams, so lines are held until then and
+// returned in the order of their last entries.
+type reverseJoiner struct {
+	engine *engine
+	queue  []*heldLine          // in the order their last entries were read
+	open   map[string]*heldLine // by stream, the line whose start is unread
+}
+
+// heldLine is a line held by reverseJoiner.
+type heldLine struct {
+	fragments []string // last first
+	whole     bool
+	others    int // entries of other streams read since its first fragment
+}
+
+func (e *engine) newReverseJoiner() *reverseJoiner {
+	if e.Container == ContainerNone {
+		return nil
+	}
+	return &reverseJoiner{engine: e, open: map[string]*heldLine{}}
+}
+
+// add takes the entry preceding those already added and returns the lines
+// that are now whole, last first.
+func (j *reverseJoiner) add(line string) []string {
+	entry, ok := j.engine.parseContainer(line)
+	for stream, held := range j.open {
+		if ok && stream == entry.stream {
+			continue
+		}
+		if held.others++; held.others > maxInterleaved {
+			held.whole = true
+			delete(j.open, stream)
+		}
+	}
+	switch {
+	case !ok:
+		j.queue = append(j.queue, &heldLine{fragments: []string{line}, whole: true})
+	case !j.engine.streamShown(entry.stream):
+	case entry.partial && j.open[entry.stream] != nil:
+		held := j.open[entry.stream]
+		held.fragments = append(held.fragments, entry.message)
+		held.others = 0
+	default:
+		if held := j.open[entry.stream]; held != nil {
+			held.whole = true
+		}
+		held := &heldLine{fragments: []string{entry.message}}
+		j.open[entry.stream] = held
+		j.queue = append(j.queue, held)
+	}
+	return j.take()
+}
+
+// flush returns the lines held at the start of the input, last first.
+func (j *reverseJoiner) flush() []string {
+	for _, held := range j.queue {
+		held.whole = true
+	}
+	clear(j.open)
+	return j.take()
+}
+
+// take removes the lines at the front of the queue that are whole and
+// returns them.
+func (j *reverseJoiner) take() []string {
+	var lines []string
+	for len(j.queue) > 0 && j.queue[0].whole {
+		fragments := j.queue[0].fragments
+		slices.Reverse(fragments)
+		lines = append(lines, strings.Join(fragments, ""))
+		j.queue = j.queue[1:]
+	}
+	return lines
+}
+
+// streamShown reports whether lines of a container stream pass
+// ContainerStream.
+func (e *engine) streamShown(stream string) bool {
+	return e.Stream == StreamAll || string(e.Stream) == stream
+}
@@ -0,0 +1,184 @@
+package command_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+// dockerLog is a json-file log holding a line split into two entries and
+// lines written to stdout and stderr.
+const dockerLog = `{"log":"starting\n","stream":"stdout","time":"2026-10-17T10:00:00.1Z"}
+{"log":"part one, ","stream":"stdout","time":"2026-10-17T10:00:00.2Z"}
+{"log":"part two\n","stream":"stdout","time":"2026-10-17T10:00:00.2Z"}
+{"log":"warning: disk\n","stream":"stderr","time":"2026-10-17T10:00:00.3Z"}
+{"log":"ready\n","stream":"stdout","time":"2026-10-17T10:00:00.4Z"}
+`
+
+// criLog is a CRI log holding a line split into three entries, with an entry
+// of the other stream between them.
+const criLog = "2026-10-17T10:00:00.100000000Z stdout F starting\n" +
+	"2026-10-17T10:00:00.200000000Z stdout P alpha \n" +
+	"2026-10-17T10:00:00.200000000Z stderr F warning: disk\n" +
+	"2026-10-17T10:00:00.200000000Z stdout P beta \n" +
+	"2026-10-17T10:00:00.200000000Z stdout F gamma\n" +
+	"2026-10-17T10:00:00.300000000Z stdout F ready\n"
+
+// ==============================================================================
+// Test Container Log Formats
+// ==============================================================================
+
+func TestTail_ContainerDocker(t *testing.T) {
+	path := writeFile(t, dockerLog)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3), command.ContainerDocker)),
+		"part one, part two\nwarning: disk\nready\n", "output")
+}
+
+func TestTail_ContainerDockerStream(t *testing.T) {
+	path := writeFile(t, dockerLog)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2), command.ContainerDocker, command.StreamStdout)),
+		"part one, part two\nready\n", "stdout")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.ContainerDocker, command.StreamStderr)),
+		"warning: disk\n", "stderr")
+}
+
+func TestTail_ContainerCRI(t *testing.T) {
+	path := writeFile(t, criLog)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3), command.ContainerCRI)),
+		"warning: disk\nalpha beta gamma\nready\n", "output")
+}
+
+func TestTail_ContainerCRIStdin(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.ContainerCRI, command.StreamStdout)).
+		WithStdinLines(
+			"2026-10-17T10:00:00.100000000Z stdout F starting",
+			"2026-10-17T10:00:00.200000000Z stdout P alpha ",
+			"2026-10-17T10:00:00.200000000Z stderr F warning: disk",
+			"2026-10-17T10:00:00.200000000Z stdout F:x beta",
+			"2026-10-17T10:00:00.300000000Z stdout F",
+		).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"alpha beta", ""})
+}
+
+func TestTail_ContainerCountsUnwrappedLines(t *testing.T) {
+	path := writeFile(t, criLog)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(1), command.ContainerCRI, command.StreamStdout,
+		command.IncludePattern("^alpha"))), "alpha beta gamma\n", "output")
+}
+
+func TestTail_ContainerKeepsOrderOfSparseStream(t *testing.T) {
+	var log strings.Builder
+	for i := 1; i <= 200; i++ {
+		stream := "stdout"
+		if i == 199 {
+			stream = "stderr"
+		}
+		fmt.Fprintf(&log, "2026-10-17T10:00:00.000000000Z %s F line %d\n", stream, i)
+	}
+	path := writeFile(t, log.String())
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3), command.ContainerCRI)),
+		"line 198\nline 199\nline 200\n", "output")
+}
+
+func TestTail_ContainerPartialAtStartOfWindow(t *testing.T) {
+	path := writeFile(t, `{"log":"first ","stream":"stdout"}
+{"log":"half\n","stream":"stdout"}
+`)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(1), command.ContainerDocker)), "first half\n", "output")
+}
+
+func TestTail_ContainerUnparsedLinesPassThrough(t *testing.T) {
+	path := writeFile(t, "not a container entry\n"+criLog)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(100), command.ContainerCRI, command.StreamStderr)),
+		"not a container entry\nwarning: disk\n", "output")
+}
+
+func TestTail_ContainerMaxLineLength(t *testing.T) {
+	path := writeFile(t, `{"log":"0123456789\n","stream":"stdout"}`+"\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.ContainerDocker, command.MaxLineLength(4))),
+		"0123…[+6 bytes]\n", "output")
+}
+
+func TestTail_ContainerSinceRuntimeTime(t *testing.T) {
+	// The messages carry no timestamps, so the window is found by the
+	// runtime's
+	clock := command.ClockFlag{Clock: fixedClock{now: time.Date(2026, 10, 17, 10, 0, 0, 250000000, time.UTC)}}
+	cri := writeFile(t, criLog)
+	docker := writeFile(t, dockerLog)
+
+	assertion.Equal(t, runFile(t, command.Tail(cri, command.ContainerCRI, command.Since(100*time.Millisecond), clock)),
+		"warning: disk\nalpha beta gamma\nready\n", "CRI file")
+	assertion.Equal(t, runFile(t, command.Tail(docker, command.ContainerDocker, command.Since(100*time.Millisecond), clock)),
+		"part one, part two\nwarning: disk\nready\n", "Docker file")
+
+	result := run.Command(command.Tail(command.ContainerCRI, command.Since(100*time.Millisecond), clock)).
+		WithStdinLines(strings.Split(strings.TrimSuffix(criLog, "\n"), "\n")...).
+		Run()
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"warning: disk", "alpha beta gamma", "ready"})
+}
+
+func TestTail_ContainerSinceMessageTime(t *testing.T) {
+	// Entries without a time of their own are timed by their message
+	path := writeFile(t, `{"log":"2026-10-17T10:00:00Z old\n","stream":"stdout"}
+{"log":"2026-10-17T10:05:00Z new\n","stream":"stdout"}
+`)
+	clock := command.ClockFlag{Clock: fixedClock{now: time.Date(2026, 10, 17, 10, 6, 0, 0, time.UTC)}}
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.ContainerDocker, command.Since(time.Minute*3), clock)),
+		"2026-10-17T10:05:00Z new\n", "output")
+}
+
+func TestTail_ContainerStreamNeedsFormat(t *testing.T) {
+	result := run.Quick(command.Tail(command.StreamStdout))
+
+	assertion.ErrorContains(t, result.Err, "ContainerStream needs a ContainerFormat")
+}
+
+func TestTail_FollowContainerJoinsFragments(t *testing.T) {
+	path := writeFile(t, "2026-10-17T10:00:00.100000000Z stdout F starting\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.Follow, command.ContainerCRI,
+		command.FollowMaxLines(1), command.ClockFlag{Clock: clock}), nil)
+	waitForOutput(t, out, "starting\n")
+	appendFile(t, path, "2026-10-17T10:00:00.200000000Z stdout P long \n")
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "2026-10-17T10:00:00.200000000Z stdout F line\n")
+	waitForExit(t, done)
+
+	assertion.Equal(t, out.String(), "starting\nlong line\n", "output")
+}
+
+func TestTail_FollowContainerFlushesIdleFragments(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.Follow, command.ContainerCRI,
+		command.FollowMaxLines(1), command.ClockFlag{Clock: clock}), nil)
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "2026-10-17T10:00:00.200000000Z stdout P torn\n")
+	clock.WaitForPolls(t, 2)
+	assertion.Equal(t, out.String(), "", "output before the flush timeout")
+	clock.Advance(time.Second)
+	waitForExit(t, done)
+
+	assertion.Equal(t, out.String(), "torn\n", "output")
+}
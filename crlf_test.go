@@ -0,0 +1,99 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+const crlfContent = "alpha\r\nbeta\r\ngamma\r\n"
+
+// ==============================================================================
+// Test CRLF Line Endings
+// ==============================================================================
+
+func TestTail_CRLFRawByDefault(t *testing.T) {
+	path := writeFile(t, crlfContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2))), "beta\r\ngamma\r\n", "output")
+}
+
+func TestTail_CRLFStrip(t *testing.T) {
+	path := writeFile(t, crlfContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2), command.CRLFStrip)), "beta\ngamma\n", "output")
+}
+
+func TestTail_CRLFStripStdin(t *testing.T) {
+	result := run.Command(command.Tail(command.CRLFStrip)).
+		WithStdinLines("alpha\r", "beta\r").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"alpha", "beta"})
+}
+
+func TestTail_CRLFStripMixedEndings(t *testing.T) {
+	path := writeFile(t, "unix\nwindows\r\nlast")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.CRLFStrip)), "unix\nwindows\nlast", "output")
+}
+
+func TestTail_CRLFPreserveWritesOriginalEndings(t *testing.T) {
+	path := writeFile(t, "unix\nwindows\r\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.CRLFPreserve)), "unix\nwindows\r\n", "output")
+}
+
+func TestTail_CRLFPreserveFiltersWithoutCarriageReturn(t *testing.T) {
+	path := writeFile(t, crlfContent)
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.CRLFPreserve, command.IncludePattern("a$"))),
+		"alpha\r\nbeta\r\ngamma\r\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.IncludePattern("a$"))), "", "output without CRLFPreserve")
+}
+
+func TestTail_CRLFPreserveContinuation(t *testing.T) {
+	path := writeFile(t, "start\r\n  more\r\n\r\nnext\r\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(1), command.CRLFPreserve,
+		command.ContinuationPattern(`^(\s|$)`))), "next\r\n", "output")
+}
+
+func TestTail_CRLFPreserveMaxLineLength(t *testing.T) {
+	path := writeFile(t, "abc\r\nabcdef\r\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.CRLFPreserve, command.MaxLineLength(3))),
+		"abc\r\nabc…[+3 bytes]\r\n", "output")
+}
+
+func TestTail_FollowCRLFStrip(t *testing.T) {
+	path := writeFile(t, "a\r\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.CRLFStrip, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "b\r")
+	appendFile(t, path, "\nc\r\n")
+	lines := waitForLines(t, out, 3)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"a", "b", "c"})
+}
+
+func TestTail_FollowCRLFPreserve(t *testing.T) {
+	path := writeFile(t, "a\r\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.CRLFPreserve,
+		command.ExcludePattern("^skip$"), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "skip\r\nb\r\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"a\r", "b\r"})
+}
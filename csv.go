@@ -0,0 +1,145 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvDelimiter returns the CSVDelimiter, ',' by default.
+func (e *engine) csvDelimiter() byte {
+	if e.CSVDelimiter == 0 {
+		return ','
+	}
+	return byte(e.CSVDelimiter)
+}
+
+// csvQuote returns the CSVQuote, '"' by default.
+func (e *engine) csvQuote() byte {
+	if e.CSVQuote == 0 {
+		return '"'
+	}
+	return byte(e.CSVQuote)
+}
+
+// checkCSV checks the CSV flags.
+func (e *engine) checkCSV() error {
+	if e.Format != FormatCSV {
+		if e.CSVDelimiter != 0 || e.CSVQuote != 0 {
+			return errors.New("CSVDelimiter and CSVQuote need FormatCSV")
+		}
+		return nil
+	}
+	if e.Continuation != "" {
+		return errors.New("ContinuationPattern can't be combined with FormatCSV")
+	}
+	for _, c := range []rune{rune(e.CSVDelimiter), rune(e.CSVQuote)} {
+		if c >= 0x80 || c == '\n' || c == '\r' {
+			return errors.New("CSVDelimiter and CSVQuote must be ASCII characters other than newlines")
+		}
+	}
+	if e.csvDelimiter() == e.csvQuote() {
+		return errors.New("CSVDelimiter and CSVQuote must differ")
+	}
+	return nil
+}
+
+// csvOpen reports whether a quoted field is still open at the end of a
+// line of a FormatCSV record, given whether one was open at its start. A
+// quote opens a field only at its start, and a doubled quote inside one
+// stands for a quote.
+func (e *engine) csvOpen(open bool, line string) bool {
+	delimiter, quote := e.csvDelimiter(), e.csvQuote()
+	fieldStart := !open
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case open && c == quote && i+1 < len(line) && line[i+1] == quote:
+			i++
+		case open && c == quote:
+			open = false
+		case !open && c == quote && fieldStart:
+			open = true
+		}
+		fieldStart = !open && line[i] == delimiter
+	}
+	return open
+}
+
+// splitCSV splits a FormatCSV record into its unquoted values.
+func (e *engine) splitCSV(record string) []string {
+	delimiter, quote := e.csvDelimiter(), e.csvQuote()
+	var values []string
+	var b strings.Builder
+	quoted, fieldStart := false, true
+	for i := 0; i < len(record); i++ {
+		switch c := record[i]; {
+		case quoted && c == quote && i+1 < len(record) && record[i+1] == quote:
+			b.WriteByte(quote)
+			i++
+		case quoted && c == quote:
+			quoted = false
+		case quoted:
+			b.WriteByte(c)
+		case c == quote && fieldStart:
+			quoted = true
+		case c == delimiter:
+			values = append(values, b.String())
+			b.Reset()
+			fieldStart = true
+			continue
+		default:
+			b.WriteByte(c)
+		}
+		fieldStart = false
+	}
+	return append(values, b.String())
+}
+
+// parseCSV parses a FormatCSV record into fields named by the header row.
+// Values past the header's columns are named by their column number,
+// counting from 1.
+func (e *engine) parseCSV(record string) (fields, bool) {
+	if e.csvColumns == nil {
+		return nil, false
+	}
+	f := fields{}
+	for i, value := range e.splitCSV(e.text(record)) {
+		name := strconv.Itoa(i + 1)
+		if i < len(e.csvColumns) {
+			name = e.csvColumns[i]
+		}
+		f[name] = value
+	}
+	return f, true
+}
+
+// setCSVHeader keeps the header row of the first input that has one.
+func (e *engine) setCSVHeader(record string) {
+	if e.csvColumns != nil {
+		return
+	}
+	e.csvHeader = record
+	e.csvColumns = e.splitCSV(e.text(record))
+}
+
+// readCSVHeader reads the header row at the start of a seekable input and
+// returns the offset just past it. When the header is not complete yet it
+// returns 0, so that a follower reads it once it is, unless the input is not
+// followed, in which case an unterminated final line completes it.
+func (e *engine) readCSVHeader(r io.ReaderAt, size int64, decoder lineDecoder) (int64, error) {
+	f := newForwardReader(r, 0, size, decoder)
+	var lines []string
+	open := false
+	for {
+		line, _, ok, err := f.next()
+		if err != nil || !ok || !f.terminated && e.following {
+			return 0, err
+		}
+		lines = append(lines, line)
+		if open = e.csvOpen(open, e.text(line)); !open || !f.terminated {
+			e.setCSVHeader(strings.Join(lines, "\n"))
+			return f.off, nil
+		}
+	}
+}
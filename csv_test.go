@@ -0,0 +1,149 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+var peopleCSV = []string{
+	"Name,Age,Note",
+	"Alice,30,plain",
+	`Bob,25,"line one`,
+	`Carol,35,looks like a row"`,
+	`Dave,41,"says ""hi"", twice"`,
+}
+
+// ==============================================================================
+// Test CSV Rows
+// ==============================================================================
+
+func TestTail_CSVKeepsHeader(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCSV, command.LineCount(2))).
+		WithStdinLines(peopleCSV...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{peopleCSV[0], peopleCSV[2], peopleCSV[3], peopleCSV[4]})
+}
+
+func TestTail_CSVFileFromEnd(t *testing.T) {
+	path := writeFile(t, strings.Join(peopleCSV, "\n")+"\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.FormatCSV, command.LineCount(2))),
+		strings.Join([]string{peopleCSV[0], peopleCSV[2], peopleCSV[3], peopleCSV[4]}, "\n")+"\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.FormatCSV, command.LineCount(10))),
+		strings.Join(peopleCSV, "\n")+"\n", "whole file")
+}
+
+func TestTail_CSVFileMatchesStdin(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,\"multi\nline header\",value\n")
+	for i := range 3000 {
+		if i%7 == 0 {
+			fmt.Fprintf(&b, "%d,\"quoted\n%d,not a row,\"\"x\"\"\",v%d\n", i, i, i)
+		} else {
+			fmt.Fprintf(&b, "%d,plain,v%d\n", i, i)
+		}
+	}
+	content := b.String()
+	path := writeFile(t, content)
+
+	for _, n := range []int{1, 5, 100, 2999, 5000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			var fromStdin bytes.Buffer
+			err := command.Tail(command.FormatCSV, command.LineCount(n)).Executor()(
+				context.Background(), strings.NewReader(content), &fromStdin, io.Discard)
+
+			assertion.NoError(t, err)
+			assertion.Equal(t, runFile(t, command.Tail(path, command.FormatCSV, command.LineCount(n))), fromStdin.String(), "output")
+		})
+	}
+}
+
+func TestTail_CSVHeaderOnly(t *testing.T) {
+	path := writeFile(t, "Name,Age\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.FormatCSV)), "Name,Age\n", "output")
+}
+
+func TestTail_CSVFields(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCSV, command.Where("Age>=30"), command.Field("Name"), command.Field("Note"))).
+		WithStdinLines(peopleCSV...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		`{"Name":"Alice","Note":"plain"}`,
+		`{"Name":"Dave","Note":"says \"hi\", twice"}`,
+	})
+}
+
+func TestTail_CSVDelimiterAndQuote(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatCSV, command.CSVDelimiter(';'), command.CSVQuote('\''),
+		command.LineCount(1), command.OutputCSV, command.Field("b"))).
+		WithStdinLines("a;b", "1;'x;y", "z'", "2;'it''s'").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"b", "it's"})
+}
+
+func TestTail_CSVSeveralFiles(t *testing.T) {
+	first := writeFile(t, "Name,Age\nAlice,30\n")
+	second := writeFile(t, "Name,Age\nBob,25\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.Quiet, command.FormatCSV)), "Name,Age\nAlice,30\nBob,25\n", "output")
+}
+
+func TestTail_CSVUnbalancedQuotes(t *testing.T) {
+	path := writeFile(t, "Name,Note\nAlice,\"open\nBob,ok\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.FormatCSV)), "Name,Note\nAlice,\"open\nBob,ok\n", "output")
+}
+
+func TestTail_FollowCSVQuotedNewline(t *testing.T) {
+	path := writeFile(t, strings.Join(peopleCSV[:2], "\n")+"\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.FormatCSV, command.Field("Note"),
+		command.ClockFlag{Clock: clock}))
+	waitForOutput(t, out, `{"Note":"plain"}`+"\n")
+	appendFile(t, path, "Eve,28,\"first\n")
+	clock.WaitForPolls(t, 2)
+	clock.Advance(time.Minute)
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "second\"\nFay,33,after\n")
+	waitForOutput(t, out, `{"Note":"plain"}`+"\n"+`{"Note":"first\nsecond"}`+"\n"+`{"Note":"after"}`+"\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_FollowCSVHeaderArrivesLater(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.FormatCSV, command.Where("Age>26"),
+		command.ClockFlag{Clock: clock}))
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "Name,Age\nBob,25\nAlice,30\n")
+	waitForOutput(t, out, "Name,Age\nAlice,30\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_CSVErrors(t *testing.T) {
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.CSVDelimiter(';'))).Err, "CSVDelimiter and CSVQuote need FormatCSV")
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.FormatCSV, command.CSVQuote(','))).Err, "must differ")
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.FormatCSV, command.CSVDelimiter('é'))).Err, "must be ASCII")
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.FormatCSV, command.IndentedContinuation)).Err,
+		"ContinuationPattern can't be combined with FormatCSV")
+}
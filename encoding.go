@@ -0,0 +1,116 @@
+package command
+
+import (
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// utf16Input reports whether inputs must be transcoded as a stream before
+// they can be split into lines.
+func (e *engine) utf16Input() bool {
+	return e.Encoding == EncodingUTF16LE || e.Encoding == EncodingUTF16BE
+}
+
+// converter returns the line conversion for the Encoding, if it works line by
+// line.
+func (e *engine) converter() func(string) string {
+	switch e.Encoding {
+	case EncodingReplace:
+		return replaceInvalid
+	case EncodingLatin1:
+		return latin1ToUTF8
+	default:
+		return nil
+	}
+}
+
+// decodeStream transcodes a UTF-16 input to UTF-8 and leaves others alone.
+func (e *engine) decodeStream(r io.Reader) io.Reader {
+	if !e.utf16Input() {
+		return r
+	}
+	return &utf16Reader{r: r, bigEndian: e.Encoding == EncodingUTF16BE}
+}
+
+// replaceInvalid replaces each invalid UTF-8 sequence with U+FFFD.
+func replaceInvalid(line string) string {
+	if utf8.ValidString(line) {
+		return line
+	}
+	return strings.ToValidUTF8(line, "�")
+}
+
+// latin1ToUTF8 converts ISO 8859-1 text, where every byte is a code point.
+func latin1ToUTF8(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		b.WriteRune(rune(line[i]))
+	}
+	return b.String()
+}
+
+// utf16Reader transcodes UTF-16 to UTF-8. A byte order mark switches to the
+// byte order it declares and is dropped, so concatenated files may differ.
+// Bytes that don't yet make a whole character are held until more arrive,
+// which lets a followed file be read as it grows.
+type utf16Reader struct {
+	r         io.Reader
+	bigEndian bool
+	raw       []byte // undecoded bytes
+	out       []byte // decoded bytes not yet read
+	buf       [blockSize]byte
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	for len(u.out) == 0 {
+		n, err := u.r.Read(u.buf[:])
+		u.raw = append(u.raw, u.buf[:n]...)
+		u.decode()
+		if len(u.out) == 0 && err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, u.out)
+	u.out = u.out[n:]
+	return n, nil
+}
+
+// decode moves whole characters from raw to out.
+func (u *utf16Reader) decode() {
+	for len(u.raw) >= 2 {
+		unit := u.unit(0)
+		switch {
+		case unit == 0xFEFF:
+			u.raw = u.raw[2:]
+			continue
+		case unit == 0xFFFE:
+			u.bigEndian = !u.bigEndian
+			u.raw = u.raw[2:]
+			continue
+		}
+		r, size := rune(unit), 2
+		if utf16.IsSurrogate(r) {
+			if len(u.raw) < 4 && unit < 0xDC00 {
+				return // wait for the low surrogate
+			}
+			high := r
+			r = utf8.RuneError
+			if unit < 0xDC00 {
+				if decoded := utf16.DecodeRune(high, rune(u.unit(2))); decoded != utf8.RuneError {
+					r, size = decoded, 4
+				}
+			}
+		}
+		u.out = utf8.AppendRune(u.out, r)
+		u.raw = u.raw[size:]
+	}
+}
+
+func (u *utf16Reader) unit(i int) uint16 {
+	if u.bigEndian {
+		return uint16(u.raw[i])<<8 | uint16(u.raw[i+1])
+	}
+	return uint16(u.raw[i+1])<<8 | uint16(u.raw[i])
+}
@@ -0,0 +1,120 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the given byte order, with an optional
+// byte order mark.
+func encodeUTF16(s string, bigEndian, bom bool) string {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return string(b)
+}
+
+// ==============================================================================
+// Test Encoding
+// ==============================================================================
+
+func TestTail_EncodingRawPassesInvalidBytes(t *testing.T) {
+	path := writeFile(t, "caf\xe9\nok\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path)), "caf\xe9\nok\n", "output")
+}
+
+func TestTail_EncodingReplace(t *testing.T) {
+	path := writeFile(t, "caf\xe9\nbad \xff\xfe end\nok\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingReplace)),
+		"caf�\nbad � end\nok\n", "output")
+}
+
+func TestTail_EncodingReplaceStdin(t *testing.T) {
+	result := run.Command(command.Tail(command.EncodingReplace)).
+		WithStdinLines("caf\xe9", "ok").Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"caf�", "ok"})
+}
+
+func TestTail_EncodingLatin1(t *testing.T) {
+	path := writeFile(t, "caf\xe9\n\xbfqu\xe9?\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingLatin1)), "café\n¿qué?\n", "output")
+}
+
+func TestTail_EncodingLatin1NotBinary(t *testing.T) {
+	path := writeFile(t, "\xe9\xe8\xea\xeb\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingLatin1, command.BinaryNotice)), "éèêë\n", "output")
+}
+
+func TestTail_EncodingLatin1TruncatesConvertedLine(t *testing.T) {
+	path := writeFile(t, "\xe9\xe9\xe9\xe9\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingLatin1, command.MaxLineLength(5))),
+		"éé…[+4 bytes]\n", "output")
+}
+
+func TestTail_EncodingUTF16LE(t *testing.T) {
+	path := writeFile(t, encodeUTF16("one\r\ntwo\r\nthree 🎉\r\n", false, false))
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2), command.EncodingUTF16LE)),
+		"two\r\nthree 🎉\r\n", "output")
+}
+
+func TestTail_EncodingUTF16BE(t *testing.T) {
+	path := writeFile(t, encodeUTF16("one\ntwo\n", true, false))
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingUTF16BE)), "one\ntwo\n", "output")
+}
+
+func TestTail_EncodingUTF16ByteOrderMarkOverridesDeclared(t *testing.T) {
+	path := writeFile(t, encodeUTF16("one\ntwo\n", true, true))
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingUTF16LE)), "one\ntwo\n", "output")
+}
+
+func TestTail_EncodingUTF16ByteOrderMarkPerFile(t *testing.T) {
+	le := writeFile(t, encodeUTF16("little\n", false, true))
+	be := writeFile(t, encodeUTF16("big\n", true, true))
+
+	assertion.Equal(t, runFile(t, command.Tail(le, be, command.Quiet, command.EncodingUTF16LE)), "little\nbig\n", "output")
+}
+
+func TestTail_EncodingUTF16LoneSurrogate(t *testing.T) {
+	path := writeFile(t, "\x00\xd8a\x00\n\x00")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.EncodingUTF16LE)), "�a\n", "output")
+}
+
+func TestTail_FollowEncodingUTF16(t *testing.T) {
+	path := writeFile(t, encodeUTF16("a\nb\nc\n", false, true))
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.LineCount(2), command.Follow,
+		command.EncodingUTF16LE, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 2)
+	appendFile(t, path, encodeUTF16("d\n", false, false)[:1])
+	appendFile(t, path, encodeUTF16("d\n", false, false)[1:])
+	lines := waitForLines(t, out, 3)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"b", "c", "d"})
+}
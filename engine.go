@@ -0,0 +1,280 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"text/template"
+	"time"
+)
+
+// engine holds a command's flags with their patterns compiled and defaults
+// applied, ready to run in either the last-N or the follow path.
+type engine struct {
+	flags
+	clock        Clock
+	continuation *regexp.Regexp
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+	where        []predicate
+	accessLog    *accessLog
+	extract      *regexp.Regexp
+	groups       []Field // the named groups of extract
+	headed       bool    // whether the header row is written
+	csvHeader    string  // the FormatCSV header row
+	csvColumns   []string
+	pods         []podSelector
+	template     *template.Template
+	widths       []int // of the OutputColumns columns written so far
+	timePattern  *regexp.Regexp
+	following    bool
+	wait         *regexp.Regexp
+	matched      string // the record that matched wait
+	done         bool   // whether a record matched wait
+	emitted      int    // records written while following
+	color        bool   // whether output is colored
+	highlights   []highlight
+	labelColors  map[string]Color // by pod label or file name
+	section      string           // the input whose section output is in
+	sectioned    bool             // whether a section has started
+	held         bool             // whether output is held to be budgeted as a whole
+	redactors    []redactor
+	stderr       io.Writer
+	hashKey      []byte // of MaskHash
+	readDecoder  lineDecoder
+	truncator    truncator
+}
+
+func newEngine(p command) (*engine, error) {
+	e := &engine{flags: p.Flags, clock: p.Flags.Clock, labelColors: map[string]Color{}}
+	if e.clock == nil {
+		e.clock = systemClock{}
+	}
+	if e.Continuation != "" {
+		re, err := regexp.Compile(string(e.Continuation))
+		if err != nil {
+			return nil, err
+		}
+		e.continuation = re
+	}
+	var err error
+	if e.include, err = compilePatterns(e.Include); err != nil {
+		return nil, err
+	}
+	if e.exclude, err = compilePatterns(e.Exclude); err != nil {
+		return nil, err
+	}
+	if err := e.compileAccessLogFlags(); err != nil {
+		return nil, err
+	}
+	if err := e.compileExtract(); err != nil {
+		return nil, err
+	}
+	if err := e.checkCSV(); err != nil {
+		return nil, err
+	}
+	if e.Format == FormatText && (len(e.Where) > 0 || len(e.Fields) > 0 || e.Template != "" || e.Output != OutputRecord) {
+		return nil, errors.New("Where, Field, Template and OutputJSON need a Format")
+	}
+	if e.Output == OutputColumns && len(e.rowFields()) == 0 {
+		return nil, errors.New("OutputColumns needs a Field")
+	}
+	if (e.Output == OutputTSV || e.Output == OutputCSV) && len(e.rowFields()) == 0 {
+		return nil, errors.New("OutputTSV and OutputCSV need a Field")
+	}
+	if e.Format != FormatSyslog && (e.MinSeverity != "" || len(e.Apps) > 0) {
+		return nil, errors.New("MinSeverity and App need FormatSyslog")
+	}
+	if e.MinSeverity != "" {
+		if _, ok := levelRank(string(e.MinSeverity)); !ok {
+			return nil, fmt.Errorf("unknown severity %q", string(e.MinSeverity))
+		}
+		e.where = append(e.where, predicate{field: "severity", op: ">=", value: string(e.MinSeverity)})
+	}
+	for _, app := range e.Apps {
+		if _, err := path.Match(string(app), ""); err != nil {
+			return nil, fmt.Errorf("invalid app %q: %w", string(app), err)
+		}
+	}
+	if len(e.Pods) > 0 && len(p.Positional) > 0 {
+		return nil, errors.New("Pod can't be combined with files")
+	}
+	for _, pod := range e.Pods {
+		s, err := compilePod(pod)
+		if err != nil {
+			return nil, err
+		}
+		e.pods = append(e.pods, s)
+	}
+	if len(e.pods) > 0 && e.Container == ContainerNone {
+		e.Container = ContainerCRI
+	}
+	if e.Stream != StreamAll && e.Container == ContainerNone {
+		return nil, errors.New("ContainerStream needs a ContainerFormat")
+	}
+	for _, where := range e.Where {
+		p, err := compilePredicate(where)
+		if err != nil {
+			return nil, err
+		}
+		e.where = append(e.where, p)
+	}
+	if e.template, err = compileTemplate(e.Template); err != nil {
+		return nil, err
+	}
+	if e.timePattern, err = regexp.Compile(e.timeFormat().Pattern); err != nil {
+		return nil, err
+	}
+	if e.highlights, err = compileHighlights(e.Highlights); err != nil {
+		return nil, err
+	}
+	if err := e.compileRedactors(); err != nil {
+		return nil, err
+	}
+	if e.WaitFor != "" {
+		if e.wait, err = regexp.Compile(string(e.WaitFor)); err != nil {
+			return nil, err
+		}
+	}
+	// Lines are truncated on output, so that filters, WaitFor and parsing
+	// see them whole and the cap doesn't change what is selected. Only lines
+	// longer than the read limit are truncated as they are read, so that
+	// they are never held whole.
+	e.truncator = newTruncator(int(e.MaxLineLength), string(e.TruncationMarker), e.CRLF == CRLFPreserve)
+	e.readDecoder.stripCR = e.CRLF == CRLFStrip
+	e.readDecoder.convert = e.converter()
+	e.readDecoder.limit = readLimit(int(e.MaxLineLength))
+	e.readDecoder.cut = e.truncator
+	return e, nil
+}
+
+func (e *engine) lineCount() int {
+	if e.Lines == 0 && e.sinceActive() {
+		return unlimited
+	}
+	if e.Lines == 0 {
+		return 10
+	}
+	return int(e.Lines)
+}
+
+func (e *engine) sleepInterval() time.Duration {
+	if e.SleepInterval <= 0 {
+		return defaultSleepInterval
+	}
+	return time.Duration(e.SleepInterval)
+}
+
+func (e *engine) recordFlushTimeout() time.Duration {
+	if e.RecordFlushTimeout <= 0 {
+		return defaultRecordFlushTimeout
+	}
+	return time.Duration(e.RecordFlushTimeout)
+}
+
+func (e *engine) partialLineTimeout() time.Duration {
+	if e.PartialLineTimeout <= 0 {
+		return defaultPartialLineTimeout
+	}
+	return time.Duration(e.PartialLineTimeout)
+}
+
+// selectLast groups lines into records and returns the last N of them that
+// pass the filter and fall in the time window. It reports whether the last
+// record returned is the input's final line and that line had no newline.
+func (e *engine) selectLast(lines []string, terminated bool) ([]string, bool) {
+	window := e.sinceActive()
+	if window && e.Container != ContainerNone {
+		lines, _ = e.entriesSince(lines)
+		window = false
+	}
+	g := e.newGrouper()
+	records := g.add(lines, time.Time{})
+	records = append(records, g.flush()...)
+	if window {
+		records = e.dropBefore(records)
+	}
+	partial := !terminated && len(records) > 0 && e.keep(records[len(records)-1])
+	return lastLines(e.filter(records), e.lineCount()), partial
+}
+
+// scanLast reads the inputs backwards, last input first, and returns the
+// last N records that pass the filter, oldest first, with the index of the
+// input each was read from. It stops reading as soon as it has found them.
+// It reports whether the last record returned is the final line of the
+// last input and that line had no newline.
+func (e *engine) scanLast(inputs []*backwardReader) ([]string, []int, bool, error) {
+	n := e.lineCount()
+	g := e.newReverseGrouper()
+	var records []string
+	var from []int
+	input := len(inputs) - 1 // being read
+	// final is set until the record holding the last line read is complete
+	read, unterminated, final, partial := false, false, true, false
+	keep := func(record string) {
+		if len(records) < n && e.keep(record) {
+			partial = partial || final && unterminated
+			records = append(records, record)
+			from = append(from, input)
+		}
+		final = false
+	}
+	for ; input >= 0; input-- {
+		for len(records) < n {
+			line, _, ok, err := inputs[input].prev()
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if !ok {
+				break
+			}
+			if !read {
+				read, unterminated = true, !inputs[input].terminated
+			}
+			for _, record := range g.add(line) {
+				keep(record)
+			}
+		}
+		if len(records) >= n {
+			break
+		}
+	}
+	input = max(input, 0)
+	for _, record := range g.flush() {
+		keep(record)
+	}
+	slices.Reverse(records)
+	slices.Reverse(from)
+	return records, from, partial, nil
+}
+
+// openBackward returns a backward reader over the current contents of f,
+// after the header row of a FormatCSV file.
+func (e *engine) openBackward(f *os.File, decoder lineDecoder) (*backwardReader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	b, err := newBackwardReader(f, info.Size(), decoder)
+	if err != nil || e.Format != FormatCSV {
+		return b, err
+	}
+	start, err := e.readCSVHeader(f, info.Size(), decoder)
+	if err != nil {
+		return nil, err
+	}
+	b.skipTo(start)
+	return b, nil
+}
+
+// lastLines returns the last n lines.
+func lastLines[T any](lines []T, n int) []T {
+	if len(lines) <= n {
+		return lines
+	}
+	return lines[len(lines)-n:]
+}
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compileExtract compiles the Extract pattern. An Extract implies
+// FormatRegex.
+func (e *engine) compileExtract() error {
+	if e.Extract != "" && e.Format == FormatText {
+		e.Format = FormatRegex
+	}
+	switch {
+	case e.Format == FormatRegex && e.Extract == "":
+		return errors.New("FormatRegex needs an Extract pattern")
+	case e.Extract != "" && e.Format != FormatRegex:
+		return errors.New("Extract needs FormatRegex")
+	case e.Extract == "":
+		return nil
+	}
+	re, err := regexp.Compile(string(e.Extract))
+	if err != nil {
+		return err
+	}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			e.groups = append(e.groups, Field(name))
+		}
+	}
+	if len(e.groups) == 0 {
+		return fmt.Errorf("invalid extract pattern %q: no named groups", string(e.Extract))
+	}
+	e.extract = re
+	return nil
+}
+
+// parseExtract parses a record into the named groups of the Extract
+// pattern. Groups that take no part in the match are left out.
+func (e *engine) parseExtract(record string) (fields, bool) {
+	m := e.extract.FindStringSubmatchIndex(record)
+	if m == nil {
+		return nil, false
+	}
+	f := fields{}
+	for i, name := range e.extract.SubexpNames() {
+		if name != "" && m[2*i] >= 0 {
+			f[name] = record[m[2*i]:m[2*i+1]]
+		}
+	}
+	return f, true
+}
+
+// rowFields returns the fields written as rows: the selected Fields, or
+// else the named groups of the Extract pattern in order.
+func (e *engine) rowFields() []Field {
+	if len(e.Fields) > 0 {
+		return e.Fields
+	}
+	return e.groups
+}
+
+// tsvEscaper escapes the values of a TSV row.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// renderRow writes the values of the row fields as a TSV or CSV row. Missing
+// fields are empty.
+func (e *engine) renderRow(f fields) string {
+	values := make([]string, len(e.rowFields()))
+	for i, name := range e.rowFields() {
+		if v, ok := f.lookup(string(name)); ok {
+			values[i] = fieldString(v)
+		}
+	}
+	return e.joinRow(values)
+}
+
+func (e *engine) joinRow(values []string) string {
+	if e.Output == OutputTSV {
+		for i, value := range values {
+			values[i] = tsvEscaper.Replace(value)
+		}
+		return strings.Join(values, "\t")
+	}
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(values)
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderOrReport renders a record for output. A record that fails to render,
+// as when the Template reaches into a field that is not an object, is
+// treated like one that does not parse: it is written unchanged, or to
+// stderr when ReportUnparsed is set, in which case it reports false.
+func (e *engine) renderOrReport(record string) (string, bool, error) {
+	line, err := e.render(record)
+	if err == nil {
+		return line, true, nil
+	}
+	if !e.ReportUnparsed {
+		return record, true, nil
+	}
+	_, err = fmt.Fprintf(e.stderr, "tail: unrendered record: %s (%v)\n", record, err)
+	return "", false, err
+}
+
+// reportUnparsed writes a record that does not parse in the Format to
+// stderr, and reports whether it did, when ReportUnparsed is set.
+func (e *engine) reportUnparsed(record string) (bool, error) {
+	if !e.ReportUnparsed || e.Format == FormatText {
+		return false, nil
+	}
+	if _, ok := e.parseFields(record); ok {
+		return false, nil
+	}
+	_, err := fmt.Fprintf(e.stderr, "tail: unparsed record: %s\n", record)
+	return true, err
+}
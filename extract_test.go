@@ -0,0 +1,113 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+const requestPattern = command.Extract(`^(?P<time>\S+) (?P<method>[A-Z]+) (?P<path>\S+) (?P<status>\d{3})(?: (?P<note>.*))?$`)
+
+var requestLog = []string{
+	"10:00:01 GET /api/users 200",
+	"10:00:02 POST /api/orders 503 upstream\ttimeout",
+	"-- restart --",
+	`10:00:03 GET /search?q=a,b 404 said "no"`,
+}
+
+// ==============================================================================
+// Test Regex Field Extraction
+// ==============================================================================
+
+func TestTail_ExtractTSV(t *testing.T) {
+	result := run.Command(command.Tail(requestPattern, command.OutputTSV)).
+		WithStdinLines(requestLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"10:00:01\tGET\t/api/users\t200\t",
+		`10:00:02` + "\tPOST\t/api/orders\t503\t" + `upstream\ttimeout`,
+		"-- restart --",
+		"10:00:03\tGET\t/search?q=a,b\t404\tsaid \"no\"",
+	})
+}
+
+func TestTail_ExtractCSV(t *testing.T) {
+	result := run.Command(command.Tail(requestPattern, command.OutputCSV, command.DropUnparsed, command.LineCount(2))).
+		WithStdinLines(requestLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"time,method,path,status,note",
+		"10:00:02,POST,/api/orders,503,upstream\ttimeout",
+		`10:00:03,GET,"/search?q=a,b",404,"said ""no"""`,
+	})
+}
+
+func TestTail_ExtractJSON(t *testing.T) {
+	result := run.Command(command.Tail(requestPattern, command.OutputJSON, command.DropUnparsed, command.Where("status>=400"))).
+		WithStdinLines(requestLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		`{"time":"10:00:02","method":"POST","path":"/api/orders","status":"503","note":"upstream\ttimeout"}`,
+		`{"time":"10:00:03","method":"GET","path":"/search?q=a,b","status":"404","note":"said \"no\""}`,
+	})
+}
+
+func TestTail_ExtractSelectedFields(t *testing.T) {
+	result := run.Command(command.Tail(requestPattern, command.OutputCSV, command.DropUnparsed,
+		command.Field("status"), command.Field("path"))).
+		WithStdinLines(requestLog[:2]...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"status,path", "200,/api/users", "503,/api/orders"})
+}
+
+func TestTail_ExtractReportUnparsed(t *testing.T) {
+	result := run.Command(command.Tail(requestPattern, command.OutputTSV, command.ReportUnparsed, command.Field("status"))).
+		WithStdinLines(requestLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"200", "503", "404"})
+	assertion.Lines(t, result.Stderr, []string{"tail: unparsed record: -- restart --"})
+}
+
+func TestTail_ExtractColumns(t *testing.T) {
+	result := run.Command(command.Tail(command.Extract(`(?P<user>\w+) logged (?P<action>in|out)`), command.OutputColumns)).
+		WithStdinLines("alice logged in", "bob logged out").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"alice  in", "bob    out"})
+}
+
+func TestTail_FollowExtractCSVHeaderOnce(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, requestPattern, command.OutputCSV,
+		command.Field("method"), command.Field("status"), command.ClockFlag{Clock: clock}))
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, requestLog[0]+"\n")
+	waitForOutput(t, out, "method,status\nGET,200\n")
+	appendFile(t, path, requestLog[1]+"\n")
+	waitForOutput(t, out, "method,status\nGET,200\nPOST,503\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_ExtractErrors(t *testing.T) {
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.Extract(`\d+`))).Err, "no named groups")
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.FormatRegex)).Err, "FormatRegex needs an Extract pattern")
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.FormatJSON, requestPattern)).Err, "Extract needs FormatRegex")
+	assertion.ErrorContains(t, run.Quick(command.Tail(command.FormatJSON, command.OutputCSV)).Err, "OutputTSV and OutputCSV need a Field")
+}
@@ -0,0 +1,292 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// fields are the named values parsed from a structured record. Nested JSON
+// objects are addressed with dotted names such as "http.status".
+type fields map[string]any
+
+// lookup returns the value of a dotted field name.
+func (f fields) lookup(name string) (any, bool) {
+	if v, ok := f[name]; ok {
+		return v, true
+	}
+	var current any = map[string]any(f)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// parseFields parses a record in the configured Format.
+func (e *engine) parseFields(record string) (fields, bool) {
+	switch e.Format {
+	case FormatJSON:
+		return parseJSON(record)
+	case FormatSyslog:
+		return parseSyslog(record)
+	case FormatLogfmt:
+		return parseLogfmt(record)
+	case FormatRegex:
+		return e.parseExtract(record)
+	case FormatCSV:
+		return e.parseCSV(record)
+	case FormatCommonLog, FormatCombinedLog, FormatAccessLog:
+		return e.accessLog.parse(record)
+	default:
+		return nil, false
+	}
+}
+
+func parseJSON(record string) (fields, bool) {
+	decoder := json.NewDecoder(strings.NewReader(record))
+	decoder.UseNumber()
+	var f fields
+	if err := decoder.Decode(&f); err != nil || decoder.More() {
+		return nil, false
+	}
+	return f, true
+}
+
+// predicate is a compiled Where condition.
+type predicate struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+var predicatePattern = regexp.MustCompile(`^\s*([^!<>=~\s]+)\s*(!=|>=|<=|=|>|<|~)\s*(.*?)\s*$`)
+
+func compilePredicate(where Where) (predicate, error) {
+	m := predicatePattern.FindStringSubmatch(string(where))
+	if m == nil {
+		return predicate{}, fmt.Errorf("invalid field condition %q", string(where))
+	}
+	p := predicate{field: m[1], op: m[2], value: m[3]}
+	if p.op == "~" {
+		re, err := regexp.Compile(p.value)
+		if err != nil {
+			return predicate{}, err
+		}
+		p.re = re
+	}
+	return p, nil
+}
+
+// match reports whether the record's fields satisfy the predicate. A missing
+// field satisfies only "!=".
+func (p predicate) match(f fields) bool {
+	v, ok := f.lookup(p.field)
+	if !ok {
+		return p.op == "!="
+	}
+	s := fieldString(v)
+	switch p.op {
+	case "~":
+		return p.re.MatchString(s)
+	case "=":
+		return compareValues(s, p.value) == 0
+	case "!=":
+		return compareValues(s, p.value) != 0
+	case ">":
+		return compareValues(s, p.value) > 0
+	case ">=":
+		return compareValues(s, p.value) >= 0
+	case "<":
+		return compareValues(s, p.value) < 0
+	default:
+		return compareValues(s, p.value) <= 0
+	}
+}
+
+// compareValues compares log levels by severity, numbers numerically and
+// anything else as strings.
+func compareValues(a, b string) int {
+	if ra, ok := levelRank(a); ok {
+		if rb, ok := levelRank(b); ok {
+			return ra - rb
+		}
+	}
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// fieldString formats a field value for comparison and rendering.
+func fieldString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// keepFields reports whether a record passes the Where conditions. Records
+// that do not parse are kept unless DropUnparsed is set.
+func (e *engine) keepFields(record string) bool {
+	if e.Format == "" {
+		return true
+	}
+	f, ok := e.parseFields(record)
+	if !ok {
+		return !bool(e.DropUnparsed)
+	}
+	for _, p := range e.where {
+		if !p.match(f) {
+			return false
+		}
+	}
+	return e.appSelected(f)
+}
+
+// render formats a selected record for output. Parsed records are rendered
+// with the Template if one is set, as columns with OutputColumns, as a
+// summary with OutputSummary, as rows with OutputTSV and OutputCSV, reduced
+// to the selected Fields, or written whole as JSON with OutputJSON;
+// everything else is written unchanged.
+func (e *engine) render(record string) (string, error) {
+	if e.rawOutput() {
+		return record, nil
+	}
+	f, ok := e.parseFields(record)
+	if !ok {
+		return record, nil
+	}
+	if e.template != nil {
+		var b bytes.Buffer
+		if err := e.template.Execute(&b, map[string]any(f)); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	if e.Output == OutputColumns {
+		return e.renderColumns(f), nil
+	}
+	if e.Output == OutputSummary {
+		return e.summarize(f), nil
+	}
+	if e.Output == OutputTSV || e.Output == OutputCSV {
+		return e.renderRow(f), nil
+	}
+	if len(e.rowFields()) == 0 {
+		b, err := json.Marshal(f)
+		return string(b), err
+	}
+	return renderJSON(f, e.rowFields()), nil
+}
+
+// rawOutput reports whether records are written as they were read.
+func (e *engine) rawOutput() bool {
+	return e.template == nil && len(e.Fields) == 0 && e.Output == OutputRecord
+}
+
+// renderJSON writes the named fields as a JSON object, in the order given.
+// Missing fields are left out.
+func renderJSON(f fields, names []Field) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for _, name := range names {
+		v, ok := f.lookup(string(name))
+		if !ok {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(string(name))
+		value, _ := json.Marshal(v)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// missingColumn stands for a field a record lacks in OutputColumns.
+const missingColumn = "-"
+
+// columns returns the values of the row fields.
+func (e *engine) columns(f fields) []string {
+	values := make([]string, len(e.rowFields()))
+	for i, name := range e.rowFields() {
+		values[i] = missingColumn
+		if v, ok := f.lookup(string(name)); ok {
+			values[i] = fieldString(v)
+		}
+	}
+	return values
+}
+
+// widen grows the column widths to fit the parsed records about to be
+// written.
+func (e *engine) widen(records []string) {
+	if e.Output != OutputColumns || e.template != nil {
+		return
+	}
+	if e.widths == nil {
+		e.widths = make([]int, len(e.rowFields()))
+	}
+	for _, record := range records {
+		f, ok := e.parseFields(record)
+		if !ok {
+			continue
+		}
+		for i, value := range e.columns(f) {
+			e.widths[i] = max(e.widths[i], utf8.RuneCountInString(value))
+		}
+	}
+}
+
+// renderColumns writes the values of the row fields, each padded to
+// its column's width but the last.
+func (e *engine) renderColumns(f fields) string {
+	var b strings.Builder
+	values := e.columns(f)
+	for i, value := range values {
+		b.WriteString(value)
+		if i < len(values)-1 {
+			b.WriteString(strings.Repeat(" ", max(e.widths[i]-utf8.RuneCountInString(value), 0)+2))
+		}
+	}
+	return b.String()
+}
+
+func compileTemplate(text Template) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return template.New("tail").Parse(string(text))
+}
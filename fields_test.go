@@ -0,0 +1,171 @@
+package command_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+var jsonLog = []string{
+	`{"time":"10:00","level":"info","service":"api","msg":"started","http":{"status":200}}`,
+	`{"time":"10:01","level":"warn","service":"db","msg":"slow query","latency":1.5}`,
+	`not json at all`,
+	`{"time":"10:02","level":"error","service":"api","msg":"failed","http":{"status":503}}`,
+	`{"time":"10:03","level":"debug","service":"api","msg":"retry"}`,
+}
+
+// ==============================================================================
+// Test JSON Field Conditions
+// ==============================================================================
+
+func TestTail_JSONWhereLevel(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.Where("level>=warn"), command.DropUnparsed)).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{jsonLog[1], jsonLog[3]})
+}
+
+func TestTail_JSONWhereAllConditions(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.DropUnparsed,
+		command.Where("service=api"), command.Where("level!=debug"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{jsonLog[0], jsonLog[3]})
+}
+
+func TestTail_JSONWhereNestedNumeric(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.DropUnparsed, command.Where("http.status >= 500"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{jsonLog[3]})
+}
+
+func TestTail_JSONWhereRegex(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.DropUnparsed, command.Where("msg~^s"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{jsonLog[0], jsonLog[1]})
+}
+
+func TestTail_JSONWhereMissingField(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.DropUnparsed, command.Where("latency>1"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{jsonLog[1]})
+}
+
+func TestTail_JSONCountsMatchingRecords(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.FormatJSON, command.Where("service=api"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{jsonLog[4]})
+}
+
+func TestTail_JSONKeepsUnparsedByDefault(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.Where("level=error"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"not json at all", jsonLog[3]})
+}
+
+func TestTail_JSONInvalidCondition(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.Where("level"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.ErrorContains(t, result.Err, `invalid field condition "level"`)
+}
+
+func TestTail_WhereNeedsFormat(t *testing.T) {
+	result := run.Command(command.Tail(command.Where("level=error"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.ErrorContains(t, result.Err, "need a Format")
+}
+
+// ==============================================================================
+// Test JSON Projection
+// ==============================================================================
+
+func TestTail_JSONSelectFields(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(3), command.FormatJSON,
+		command.Field("level"), command.Field("http.status"), command.Field("msg"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"not json at all",
+		`{"level":"error","http.status":503,"msg":"failed"}`,
+		`{"level":"debug","msg":"retry"}`,
+	})
+}
+
+func TestTail_JSONTemplate(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.FormatJSON, command.DropUnparsed,
+		command.Template("{{.time}} [{{.level}}] {{.msg}}"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"10:02 [error] failed",
+		"10:03 [debug] retry",
+	})
+}
+
+func TestTail_JSONTemplateNested(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(1), command.FormatJSON, command.Where("http.status>0"),
+		command.DropUnparsed, command.Template("{{.http.status}} {{.msg}}"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"503 failed"})
+}
+
+func TestTail_JSONTemplateFailsOnOneRecord(t *testing.T) {
+	// http is a string in the first record, so the template can't index it
+	lines := []string{`{"http":"down","msg":"a"}`, `{"http":{"status":200},"msg":"b"}`}
+	result := run.Command(command.Tail(command.FormatJSON, command.Template("{{.http.status}} {{.msg}}"))).
+		WithStdinLines(lines...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{`{"http":"down","msg":"a"}`, "200 b"})
+
+	result = run.Command(command.Tail(command.FormatJSON, command.Template("{{.http.status}} {{.msg}}"), command.ReportUnparsed)).
+		WithStdinLines(lines...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"200 b"})
+	assertion.Count(t, result.Stderr, 1)
+	assertion.Equal(t, strings.HasPrefix(result.Stderr[0], `tail: unrendered record: {"http":"down","msg":"a"} (`), true, "reported")
+}
+
+func TestTail_JSONInvalidTemplate(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatJSON, command.Template("{{.msg"))).
+		WithStdinLines(jsonLog...).
+		Run()
+
+	assertion.ErrorContains(t, result.Err, "template")
+}
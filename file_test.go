@@ -0,0 +1,163 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	gloo "github.com/gloo-foo/framework"
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// runFile runs cmd, which reads its input from files, and returns its output.
+func runFile(t *testing.T, cmd gloo.Command) string {
+	t.Helper()
+	var out bytes.Buffer
+	err := cmd.Executor()(context.Background(), strings.NewReader(""), &out, io.Discard)
+	assertion.NoError(t, err)
+	return out.String()
+}
+
+// numbered returns n lines "prefix 1" to "prefix n", each ending in a newline.
+func numbered(prefix string, n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%s %d\n", prefix, i)
+	}
+	return b.String()
+}
+
+// ==============================================================================
+// Test Reading Files From the End
+// ==============================================================================
+
+func TestTail_FileLastLines(t *testing.T) {
+	path := writeFile(t, "a\nb\nc\nd\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2))), "c\nd\n", "output")
+}
+
+func TestTail_FileWithoutTrailingNewline(t *testing.T) {
+	path := writeFile(t, "a\nb\nc")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2))), "b\nc", "output")
+}
+
+func TestTail_FileEmpty(t *testing.T) {
+	path := writeFile(t, "")
+
+	assertion.Equal(t, runFile(t, command.Tail(path)), "", "output")
+}
+
+func TestTail_FileOnlyNewlines(t *testing.T) {
+	path := writeFile(t, "\n\n\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2))), "\n\n", "output")
+}
+
+func TestTail_FileSpanningBlocks(t *testing.T) {
+	path := writeFile(t, numbered("line", 20000))
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3))),
+		"line 19998\nline 19999\nline 20000\n", "output")
+}
+
+func TestTail_FileLongLineSpanningBlocks(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	path := writeFile(t, "first\n"+long+"\nlast\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2))), long+"\nlast\n", "output")
+}
+
+func TestTail_MultipleFilesConcatenated(t *testing.T) {
+	first := writeFile(t, "a\nb\n")
+	second := writeFile(t, "c\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.Quiet, command.LineCount(2))), "b\nc\n", "output")
+}
+
+func TestTail_FileMatchesStdinPath(t *testing.T) {
+	content := numbered("entry", 5000)
+	path := writeFile(t, content)
+
+	var fromStdin bytes.Buffer
+	err := command.Tail(command.LineCount(7)).Executor()(
+		context.Background(), strings.NewReader(content), &fromStdin, io.Discard)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(7))), fromStdin.String(), "output")
+}
+
+// ==============================================================================
+// Test Filters and Records When Reading From the End
+// ==============================================================================
+
+func TestTail_FileIncludeScansBackwards(t *testing.T) {
+	var content strings.Builder
+	for i := 1; i <= 30000; i++ {
+		level := "INFO"
+		if i%1000 == 0 {
+			level = "ERROR"
+		}
+		fmt.Fprintf(&content, "%s %d\n", level, i)
+	}
+	path := writeFile(t, content.String())
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3), command.IncludePattern("^ERROR"))),
+		"ERROR 28000\nERROR 29000\nERROR 30000\n", "output")
+}
+
+func TestTail_FileIncludeFewerMatchesThanCount(t *testing.T) {
+	path := writeFile(t, "ERROR a\nINFO b\nINFO c\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.IncludePattern("ERROR"))), "ERROR a\n", "output")
+}
+
+func TestTail_FileContinuationRecords(t *testing.T) {
+	path := writeFile(t, "  orphan\nstart 1\n  a\nstart 2\n  b\n  c\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(1), command.IndentedContinuation)),
+		"start 2\n  b\n  c\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(5), command.IndentedContinuation)),
+		"  orphan\nstart 1\n  a\nstart 2\n  b\n  c\n", "output")
+}
+
+func TestTail_FileContinuationAcrossFiles(t *testing.T) {
+	first := writeFile(t, "start\n")
+	second := writeFile(t, "  continued\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.Quiet, command.LineCount(1), command.IndentedContinuation)),
+		"start\n  continued\n", "output")
+}
+
+func TestTail_FileMaxOutputBytes(t *testing.T) {
+	path := writeFile(t, "a\n"+strings.Repeat("x", 100000)+"\nb\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3), command.MaxOutputBytes(100))),
+		"[... 2 lines (100003 bytes) truncated ...]\nb\n", "output")
+}
+
+func TestTail_FileMaxOutputBytesPartialLine(t *testing.T) {
+	// The final line is written without a newline, which takes no space
+	path := writeFile(t, "a\nbbbb")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(2), command.MaxOutputBytes(6))),
+		"a\nbbbb", "output")
+}
+
+func TestTail_FileMaxLineLength(t *testing.T) {
+	path := writeFile(t, "first\n"+strings.Repeat("x", 1000000)+"\r\nlast line\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.LineCount(3), command.MaxLineLength(4), command.CRLFStrip)),
+		"firs…[+1 bytes]\nxxxx…[+999996 bytes]\nlast…[+5 bytes]\n", "output")
+}
+
+func TestTail_FileMaxLineLengthWithoutTrailingNewline(t *testing.T) {
+	path := writeFile(t, strings.Repeat("y", 100000))
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.MaxLineLength(2))), "yy…[+99998 bytes]", "output")
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compilePatterns compiles each pattern.
+func compilePatterns[T ~string](patterns []T) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(string(pattern))
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = re
+	}
+	return compiled, nil
+}
+
+// keep reports whether a record passes the filters. A record is kept when it
+// matches any include pattern, or there are none, matches no exclude pattern,
+// and its fields satisfy the Where conditions.
+func (e *engine) keep(record string) bool {
+	record = e.text(record)
+	return e.keepPatterns(record) && e.keepFields(record)
+}
+
+// text returns a record as patterns see it. With CRLFPreserve its lines keep
+// their carriage returns for output, so they are dropped here.
+func (e *engine) text(record string) string {
+	if e.CRLF != CRLFPreserve {
+		return record
+	}
+	return strings.TrimSuffix(strings.ReplaceAll(record, "\r\n", "\n"), "\r")
+}
+
+func (e *engine) keepPatterns(record string) bool {
+	for _, re := range e.exclude {
+		if re.MatchString(record) {
+			return false
+		}
+	}
+	if len(e.include) == 0 {
+		return true
+	}
+	for _, re := range e.include {
+		if re.MatchString(record) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the records that pass the filters.
+func (e *engine) filter(records []string) []string {
+	kept := records[:0:0]
+	for _, record := range records {
+		if e.keep(record) {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,384 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSleepInterval      = time.Second
+	defaultPartialLineTimeout = time.Second
+)
+
+// follow prints the last records of each file, then polls the files for
+// appended lines. With headers, each file writes in its own section, and
+// appended lines are preceded by their file's header whenever the file
+// written last was another. The last records of the files share one
+// MaxOutputBytes budget.
+func (e *engine) follow(ctx context.Context, files []*os.File, stdout io.Writer) error {
+	began := e.clock.Now()
+	held := e.holdOutput(stdout)
+	followers, err := e.startFollowers(files, held)
+	if err := held.release(err); err != nil || e.done {
+		return err
+	}
+	return e.poll(ctx, began, followers, nil)
+}
+
+// startFollowers writes the last records of each file and returns followers
+// for the files it follows. It stops at a record that matches WaitFor.
+func (e *engine) startFollowers(files []*os.File, stdout io.Writer) ([]*lineFollower, error) {
+	headers := e.showHeaders(len(files))
+	var followers []*lineFollower
+	for _, f := range files {
+		start := e.followFile
+		if e.utf16Input() {
+			start = e.followDecoded
+		}
+		out := stdout
+		if headers {
+			w := e.newSectionWriter(stdout, f.Name())
+			if err := w.start(); err != nil {
+				return nil, err
+			}
+			out = w
+		}
+		follower, err := start(out, f)
+		if err != nil || e.done {
+			return nil, err
+		}
+		if follower != nil {
+			followers = append(followers, follower)
+		}
+	}
+	return followers, nil
+}
+
+// poll polls followers for appended lines until ctx is cancelled, a record
+// matches WaitFor or a limit is reached. A record still waiting for
+// continuation lines is emitted once it has been idle for the record flush
+// timeout, and a line still waiting for its newline as PartialLines says.
+// discover, if set, returns followers for inputs that appeared since the
+// last poll.
+func (e *engine) poll(ctx context.Context, began time.Time, followers []*lineFollower, discover func() ([]*lineFollower, error)) error {
+	updated := began
+	for {
+		select {
+		case <-ctx.Done():
+			return e.stopped(ctx)
+		case <-e.clock.After(e.sleepInterval()):
+		}
+		if discover != nil {
+			found, err := discover()
+			if err != nil {
+				return err
+			}
+			followers = append(followers, found...)
+		}
+		now := e.clock.Now()
+		for _, f := range followers {
+			if err := e.truncated(f); err != nil {
+				return err
+			}
+			lines, err := f.drain(now)
+			if err != nil {
+				return err
+			}
+			if err := e.advance(f.out, now, f, lines); err != nil || e.done {
+				return err
+			}
+			if f.updated.After(updated) {
+				updated = f.updated
+			}
+		}
+		if err := e.expired(began, updated, now); err != nil {
+			return err
+		}
+	}
+}
+
+// advance writes the records completed by lines f read at now, and the
+// records and partial line that have waited long enough. It stops after a
+// record that matches WaitFor, or with ErrMaxLines at FollowMaxLines.
+func (e *engine) advance(stdout io.Writer, now time.Time, f *lineFollower, lines []string) error {
+	if f.flushed != nil && len(lines) > 0 {
+		if err := e.finishPartial(stdout, f, lines[0]); err != nil || e.done {
+			return err
+		}
+		lines = lines[1:]
+	}
+	records := f.records.add(lines, now)
+	if f.records.idle(now) {
+		records = append(records, f.records.flush()...)
+	}
+	records, limited := e.untilLimit(e.filter(records))
+	records, matched := e.untilMatch(records)
+	if err := e.emit(stdout, now, records, false); err != nil || matched {
+		return err
+	}
+	if limited {
+		return e.limitReached(limited)
+	}
+	return e.flushPartial(stdout, now, f)
+}
+
+// followFile writes the last records of f and returns a follower for the
+// lines appended after them, or nil when f is not to be shown.
+func (e *engine) followFile(stdout io.Writer, f *os.File) (*lineFollower, error) {
+	return e.followFiles(stdout, nil, f)
+}
+
+// followFiles is followFile for f read as the continuation of earlier
+// files, as a container's logs from before it restarted are.
+func (e *engine) followFiles(stdout io.Writer, earlier []*os.File, f *os.File) (*lineFollower, error) {
+	var inputs []*backwardReader
+	for _, ef := range earlier {
+		decoder, show, err := e.inspectFile(stdout, ef)
+		if err != nil {
+			return nil, err
+		}
+		if !show {
+			continue
+		}
+		b, err := e.openBackward(ef, decoder)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, b)
+	}
+	decoder, show, err := e.inspectFile(stdout, f)
+	if !show || err != nil {
+		return nil, err
+	}
+	records, offset, err := e.scanComplete(inputs, f, decoder)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.write(stdout, records, false); err != nil {
+		return nil, err
+	}
+	if err := e.scanExisting(f, offset, decoder); err != nil || e.done {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	follower := e.newFollower(stdout, f, decoder)
+	follower.records.header = offset == 0
+	return follower, nil
+}
+
+// newFollower returns a follower for the lines read from r, whose records
+// are written to stdout. r is taken to be past the input's header. When r
+// is a file, the follower watches it for truncation.
+func (e *engine) newFollower(stdout io.Writer, r io.Reader, decoder lineDecoder) *lineFollower {
+	g := e.newGrouper()
+	g.header = false
+	follower := &lineFollower{
+		reader:  bufio.NewReaderSize(r, blockSize),
+		line:    lineBuffer{decoder: decoder},
+		records: g,
+		out:     stdout,
+	}
+	if file, ok := r.(*os.File); ok {
+		if offset, err := file.Seek(0, io.SeekCurrent); err == nil {
+			follower.file, follower.offset = file, offset
+		}
+	}
+	return follower
+}
+
+// truncated starts reading f's file again from its start when the file has
+// shrunk below what was read, as when logrotate's copytruncate empties it,
+// and then tells stderr as GNU tail does.
+func (e *engine) truncated(f *lineFollower) error {
+	if f.file == nil {
+		return nil
+	}
+	info, err := f.file.Stat()
+	if err != nil || info.Size() >= f.offset {
+		return err
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	f.reader.Reset(f.file)
+	f.line.take()
+	f.offset, f.flushed = 0, nil
+	_, err = fmt.Fprintf(e.stderr, "tail: %s: file truncated\n", f.file.Name())
+	return err
+}
+
+// followDecoded is followFile for a file that must be transcoded, which is
+// read from its start instead of its end.
+func (e *engine) followDecoded(stdout io.Writer, f *os.File) (*lineFollower, error) {
+	follower := e.newFollower(stdout, e.decodeStream(f), e.readDecoder)
+	lines, err := follower.drain(e.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	decoder, show, err := e.inspect(stdout, f.Name(), sampleLines(lines))
+	if !show || err != nil {
+		return nil, err
+	}
+	follower.line.decoder = decoder
+	follower.records.header = len(lines) == 0
+	lines = decoder.escapeLines(lines)
+	records, _ := e.selectLast(lines, true)
+	if err := e.write(stdout, records, false); err != nil {
+		return nil, err
+	}
+	if e.WaitScanExisting {
+		g := e.newGrouper()
+		e.untilMatch(e.filter(append(g.add(lines, time.Time{}), g.flush()...)))
+	}
+	return follower, nil
+}
+
+// scanComplete selects the last records of f's complete lines, read after
+// the earlier inputs, or those in the time window, and returns them with the
+// offset where following should begin. A final line still missing its
+// newline is left for the follower, which holds it until the newline
+// arrives.
+func (e *engine) scanComplete(earlier []*backwardReader, f *os.File, decoder lineDecoder) ([]string, int64, error) {
+	b, err := e.openBackward(f, decoder)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset := b.end + 1 // just past the final newline
+	if !b.terminated {
+		if _, offset, _, err = b.prev(); err != nil {
+			return nil, 0, err
+		}
+		b.terminated = true // what is left ends with a newline
+	}
+	if e.sinceActive() {
+		var records []string
+		for _, input := range earlier {
+			since, _, err := e.readSince(input.r, input.size, input.decoder)
+			if err != nil {
+				return nil, 0, err
+			}
+			records = append(records, since...)
+		}
+		since, _, err := e.readSince(f, offset, decoder)
+		return lastLines(append(records, since...), e.lineCount()), offset, err
+	}
+	records, _, _, err := e.scanLast(append(earlier, b))
+	return records, offset, err
+}
+
+// flushPartial writes the line f holds before its newline arrives when
+// PartialLines allows, and then whatever is added to it, as GNU tail writes
+// bytes as they arrive. Whether the line passes the filter is decided by its
+// first part. Grouped and parsed records are only written whole.
+func (e *engine) flushPartial(stdout io.Writer, now time.Time, f *lineFollower) error {
+	if !e.partialShown() {
+		return nil
+	}
+	switch e.PartialLines {
+	case PartialComplete:
+		return nil
+	case PartialIdle:
+		if now.Sub(f.updated) < e.partialLineTimeout() {
+			return nil
+		}
+	}
+	return e.writePartial(stdout, now, f)
+}
+
+// partialShown reports whether lines are written as they are read, so that
+// part of one can be written before the rest. Lines are redacted whole, so
+// that no secret is split across writes.
+func (e *engine) partialShown() bool {
+	return e.continuation == nil && e.Format == FormatText && e.Container == ContainerNone && len(e.redactors) == 0
+}
+
+// writePartial writes the part of the line f holds that is not yet written.
+func (e *engine) writePartial(stdout io.Writer, now time.Time, f *lineFollower) error {
+	if !f.line.pending() {
+		return nil
+	}
+	line := f.line.peek()
+	text := e.truncator.truncate(line)
+	written := ""
+	if f.flushed == nil {
+		f.flushed = &partialLine{kept: e.keep(line)}
+		written = e.stamp(now, "")
+	}
+	if f.flushed.kept {
+		written += unwritten(f.flushed.text, text)
+	}
+	f.flushed.text = text
+	_, err := io.WriteString(stdout, written)
+	return err
+}
+
+// finishPartial writes the rest of a line flushed by flushPartial, now that
+// its newline has arrived, and matches the whole line against WaitFor.
+func (e *engine) finishPartial(stdout io.Writer, f *lineFollower, line string) error {
+	kept, written := f.flushed.kept, f.flushed.text
+	f.flushed = nil
+	if !kept {
+		return nil
+	}
+	_, limited := e.untilLimit([]string{line})
+	e.untilMatch([]string{line})
+	if _, err := io.WriteString(stdout, unwritten(written, e.truncator.truncate(line))+"\n"); err != nil {
+		return err
+	}
+	return e.limitReached(limited)
+}
+
+// unwritten returns the part of text after what was already written of it.
+// Nothing more is written when text no longer begins the same way, as when
+// a truncated line's marker changes.
+func unwritten(written, text string) string {
+	if !strings.HasPrefix(text, written) {
+		return ""
+	}
+	return text[len(written):]
+}
+
+// partialLine is the part of a line already written before its newline.
+type partialLine struct {
+	text string
+	kept bool
+}
+
+// lineFollower reads complete lines from a growing file. A trailing line
+// without its newline is held back until the rest of it is written.
+type lineFollower struct {
+	reader  *bufio.Reader
+	line    lineBuffer
+	records *grouper
+	updated time.Time    // when bytes were last read
+	flushed *partialLine // the part of the held line already written
+	out     io.Writer    // where its records are written
+	file    *os.File     // the file read, when it can be truncated
+	offset  int64        // how much of file has been read
+}
+
+// drain returns the complete lines that can be read without blocking on EOF.
+func (f *lineFollower) drain(now time.Time) ([]string, error) {
+	var lines []string
+	for {
+		complete, n, err := f.line.readFrom(f.reader)
+		f.offset += int64(n)
+		if n > 0 {
+			f.updated = now
+		}
+		if !complete {
+			if err == io.EOF {
+				err = nil
+			}
+			return lines, err
+		}
+		lines = append(lines, f.line.take())
+	}
+}
@@ -0,0 +1,445 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gloo "github.com/gloo-foo/framework"
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// ==============================================================================
+// Follow Test Helpers
+// ==============================================================================
+
+// fixedClock always reports the same time and polls every millisecond.
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time                       { return c.now }
+func (c fixedClock) After(time.Duration) <-chan time.Time { return time.After(time.Millisecond) }
+
+// manualClock reports a time that only moves when the test advances it, and
+// polls every millisecond. It counts the times it is read, once per poll.
+type manualClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	reads int
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reads++
+	return c.now
+}
+
+// WaitForPolls waits until follow mode has read the clock n more times, so
+// that a poll has started and finished since it was called.
+func (c *manualClock) WaitForPolls(t *testing.T, n int) {
+	t.Helper()
+	c.mu.Lock()
+	target := c.reads + n
+	c.mu.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		reads := c.reads
+		c.mu.Unlock()
+		if reads >= target {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d polls", n)
+}
+
+func (c *manualClock) After(time.Duration) <-chan time.Time { return time.After(time.Millisecond) }
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// syncBuffer is a bytes.Buffer that is safe to read while a command writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := strings.TrimSuffix(b.buf.String(), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startFollow runs cmd in the background and returns its output buffer and a
+// function that cancels it and returns its error.
+func startFollow(t *testing.T, cmd gloo.Command) (*syncBuffer, func() error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Executor()(ctx, strings.NewReader(""), out, io.Discard) }()
+	return out, func() error {
+		cancel()
+		return <-done
+	}
+}
+
+// waitForLines waits until out holds n lines.
+func waitForLines(t *testing.T, out *syncBuffer, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if lines := out.Lines(); len(lines) >= n {
+			return lines
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d lines, got %q", n, out.Lines())
+	return nil
+}
+
+// waitForOutput waits until out holds want.
+func waitForOutput(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if out.String() == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %q, got %q", want, out.String())
+}
+
+// ==============================================================================
+// Test Follow Mode
+// ==============================================================================
+
+func TestTail_FollowAppendedLines(t *testing.T) {
+	path := writeFile(t, "a\nb\nc\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.LineCount(2), command.Follow, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 2)
+	appendFile(t, path, "d\ne\n")
+	lines := waitForLines(t, out, 4)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"b", "c", "d", "e"})
+}
+
+func TestTail_FollowHoldsPartialLine(t *testing.T) {
+	path := writeFile(t, "a\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "par")
+	appendFile(t, path, "tial\nnext\n")
+	lines := waitForLines(t, out, 3)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"a", "partial", "next"})
+}
+
+func TestTail_FollowTruncatedFile(t *testing.T) {
+	path := writeFile(t, "a\nb\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+	ctx, cancel := context.WithCancel(context.Background())
+	out, stderr := &syncBuffer{}, &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Tail(path, command.Follow, command.ClockFlag{Clock: clock}).Executor()(ctx, strings.NewReader(""), out, stderr)
+	}()
+	waitForOutput(t, out, "a\nb\n")
+	// As logrotate's copytruncate empties it
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "c\n")
+	waitForOutput(t, out, "a\nb\nc\n")
+	cancel()
+
+	assertion.NoError(t, <-done)
+	assertion.Equal(t, stderr.String(), "tail: "+path+": file truncated\n", "stderr")
+}
+
+// ==============================================================================
+// Test Arrival Timestamps
+// ==============================================================================
+
+func TestTail_FollowTimestampRFC3339Nano(t *testing.T) {
+	path := writeFile(t, "a\nb\n")
+	clock := fixedClock{now: time.Date(2026, 10, 17, 12, 30, 0, 5000, time.UTC)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow,
+		command.TimestampRFC3339Nano, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 2)
+	appendFile(t, path, "c\n")
+	lines := waitForLines(t, out, 3)
+
+	assertion.NoError(t, stop())
+	// Lines already in the file were not read while following
+	assertion.Lines(t, lines, []string{"a", "b", "2026-10-17T12:30:00.000005Z c"})
+}
+
+func TestTail_FollowTimestampUnixMillis(t *testing.T) {
+	path := writeFile(t, "a\n")
+	clock := fixedClock{now: time.UnixMilli(1760704200123)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow,
+		command.TimestampUnixMillis, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "b\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"a", "1760704200123 b"})
+}
+
+func TestTail_FollowTimestampCustomLayout(t *testing.T) {
+	path := writeFile(t, "a\n")
+	clock := fixedClock{now: time.Date(2026, 10, 17, 8, 5, 9, 0, time.UTC)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow,
+		command.TimestampLayout(time.TimeOnly), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "b\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"a", "08:05:09 b"})
+}
+
+func TestTail_TimestampIgnoredWithoutFollow(t *testing.T) {
+	path := writeFile(t, "a\nb\n")
+
+	var out bytes.Buffer
+	err := command.Tail(path, command.TimestampRFC3339Nano).Executor()(context.Background(), nil, &out, io.Discard)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, out.String(), "a\nb\n", "output")
+}
+
+func TestTail_FollowIgnoredForStdin(t *testing.T) {
+	// Like GNU tail, -f on a pipe reads to EOF and exits
+	var out bytes.Buffer
+	err := command.Tail(command.Follow, command.TimestampUnixMillis).Executor()(
+		context.Background(), strings.NewReader("a\nb\n"), &out, io.Discard)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, out.String(), "a\nb\n", "output")
+}
+
+// ==============================================================================
+// Test Multiline Records in Follow Mode
+// ==============================================================================
+
+func TestTail_FollowEmitsWholeRecords(t *testing.T) {
+	path := writeFile(t, "old\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.IndentedContinuation,
+		command.RecordFlushTimeout(time.Second), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "panic: boom\n\tmain.go:1\n")
+	appendFile(t, path, "\tmain.go:2\nnext\n")
+	lines := waitForLines(t, out, 4)
+
+	// The "next" record may still receive continuation lines
+	assertion.Lines(t, lines, []string{"old", "panic: boom", "\tmain.go:1", "\tmain.go:2"})
+
+	clock.Advance(time.Second)
+	lines = waitForLines(t, out, 5)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"old", "panic: boom", "\tmain.go:1", "\tmain.go:2", "next"})
+}
+
+func TestTail_FollowRecordTimestampOnFirstLine(t *testing.T) {
+	path := writeFile(t, "old\n")
+	clock := fixedClock{now: time.UnixMilli(42)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.IndentedContinuation,
+		command.TimestampUnixMillis, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "start\n  cont\nnext\n")
+	lines := waitForLines(t, out, 3)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"old", "42 start", "  cont"})
+}
+
+// ==============================================================================
+// Test Filters in Follow Mode
+// ==============================================================================
+
+func TestTail_FollowAppliesFilter(t *testing.T) {
+	path := writeFile(t, "ERROR old\nINFO a\nINFO b\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.LineCount(1), command.Follow,
+		command.IncludePattern("ERROR"), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "INFO c\nERROR new\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"ERROR old", "ERROR new"})
+}
+
+func TestTail_FollowStartsAfterPartialLine(t *testing.T) {
+	path := writeFile(t, "a\nb\npart")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.LineCount(1), command.Follow, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "ial\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"b", "partial"})
+}
+
+func TestTail_FollowMaxLineLength(t *testing.T) {
+	path := writeFile(t, "abcdef\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.MaxLineLength(3), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, strings.Repeat("g", 50000))
+	appendFile(t, path, strings.Repeat("h", 50000)+"\n")
+	lines := waitForLines(t, out, 2)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{"abc…[+3 bytes]", "ggg…[+99997 bytes]"})
+}
+
+// ==============================================================================
+// Test Partial Line Policies
+// ==============================================================================
+
+func TestTail_FollowPartialImmediate(t *testing.T) {
+	path := writeFile(t, "start\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.PartialImmediate,
+		command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "Downloading... 45%")
+	waitForOutput(t, out, "start\nDownloading... 45%")
+	appendFile(t, path, " 90%")
+	waitForOutput(t, out, "start\nDownloading... 45% 90%")
+	appendFile(t, path, "\n")
+	waitForOutput(t, out, "start\nDownloading... 45% 90%\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_FollowPartialIdleTimeout(t *testing.T) {
+	path := writeFile(t, "start\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.PartialLineTimeout(5*time.Second),
+		command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "45%")
+	clock.WaitForPolls(t, 3)
+	clock.Advance(4 * time.Second)
+	clock.WaitForPolls(t, 3)
+	assertion.Equal(t, out.String(), "start\n", "output before the timeout")
+
+	clock.Advance(time.Second)
+	waitForOutput(t, out, "start\n45%")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_FollowPartialIdleTimeoutRestartsOnNewBytes(t *testing.T) {
+	path := writeFile(t, "start\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.PartialLineTimeout(5*time.Second),
+		command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "4")
+	clock.WaitForPolls(t, 3)
+	clock.Advance(4 * time.Second)
+	appendFile(t, path, "5%")
+	clock.WaitForPolls(t, 3)
+	clock.Advance(4 * time.Second)
+	clock.WaitForPolls(t, 3)
+	assertion.Equal(t, out.String(), "start\n", "output before the timeout")
+
+	clock.Advance(time.Second)
+	waitForOutput(t, out, "start\n45%")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_FollowPartialComplete(t *testing.T) {
+	path := writeFile(t, "start\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.PartialComplete,
+		command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "45%")
+	clock.WaitForPolls(t, 3)
+	clock.Advance(time.Hour)
+	clock.WaitForPolls(t, 3)
+	assertion.Equal(t, out.String(), "start\n", "output before the newline")
+
+	appendFile(t, path, " done\n")
+	waitForOutput(t, out, "start\n45% done\n")
+
+	assertion.NoError(t, stop())
+}
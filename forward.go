@@ -0,0 +1,59 @@
+package command
+
+import (
+	"bufio"
+	"io"
+)
+
+// forwardReader yields the lines of a seekable input from a given offset,
+// together with the offset where each line starts. It is the counterpart of
+// backwardReader for reading on from a point found by seeking.
+type forwardReader struct {
+	reader     *bufio.Reader
+	line       lineBuffer
+	off        int64
+	terminated bool // whether the last line returned ended with a newline
+}
+
+func newForwardReader(r io.ReaderAt, off, size int64, decoder lineDecoder) *forwardReader {
+	return &forwardReader{
+		reader:     bufio.NewReaderSize(io.NewSectionReader(r, off, size-off), blockSize),
+		line:       lineBuffer{decoder: decoder},
+		off:        off,
+		terminated: true,
+	}
+}
+
+// next returns the next line and the offset where it starts. A final line
+// without a newline is returned as a line. It returns false at the end of
+// the input.
+func (f *forwardReader) next() (string, int64, bool, error) {
+	complete, n, err := f.line.readFrom(f.reader)
+	if !complete && err != io.EOF {
+		return "", 0, false, err
+	}
+	if !complete && !f.line.pending() {
+		return "", 0, false, nil
+	}
+	start := f.off
+	f.off += int64(n)
+	f.terminated = complete
+	return f.line.take(), start, true, nil
+}
+
+// skipPartial moves past the rest of the line the reader started in, unless
+// it started at the beginning of a line.
+func (f *forwardReader) skipPartial(r io.ReaderAt) error {
+	if f.off == 0 {
+		return nil
+	}
+	var prev [1]byte
+	if _, err := r.ReadAt(prev[:], f.off-1); err != nil {
+		return err
+	}
+	if prev[0] == '\n' {
+		return nil
+	}
+	_, _, _, err := f.next()
+	return err
+}
@@ -0,0 +1,116 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// ==============================================================================
+// Test Byte-Exact Output Against GNU tail
+// ==============================================================================
+
+// TestTail_GoldenGNU compares output with GNU tail's. Each testdata/golden
+// input NAME.in has outputs NAME.nN.out captured with `tail -n N NAME.in`.
+func TestTail_GoldenGNU(t *testing.T) {
+	outputs, err := filepath.Glob(filepath.Join("testdata", "golden", "*.out"))
+	assertion.NoError(t, err)
+	if len(outputs) == 0 {
+		t.Fatal("no golden files")
+	}
+	for _, output := range outputs {
+		name, count, _ := strings.Cut(strings.TrimSuffix(filepath.Base(output), ".out"), ".n")
+		n, err := strconv.Atoi(count)
+		assertion.NoError(t, err)
+		input := filepath.Join("testdata", "golden", name+".in")
+		want, err := os.ReadFile(output)
+		assertion.NoError(t, err)
+
+		t.Run(name+"/n"+count+"/file", func(t *testing.T) {
+			assertion.Equal(t, runFile(t, command.Tail(input, command.LineCount(n))), string(want), "output")
+		})
+		t.Run(name+"/n"+count+"/stdin", func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			assertion.NoError(t, err)
+			var out bytes.Buffer
+			err = command.Tail(command.LineCount(n)).Executor()(context.Background(), bytes.NewReader(content), &out, io.Discard)
+			assertion.NoError(t, err)
+			assertion.Equal(t, out.String(), string(want), "output")
+		})
+	}
+}
+
+// ==============================================================================
+// Test Partial Lines in Follow Mode
+// ==============================================================================
+
+// advanceUntil advances clock a second at a time until out holds want.
+func advanceUntil(t *testing.T, clock *manualClock, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if out.String() == want {
+			return
+		}
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %q, got %q", want, out.String())
+}
+
+func TestTail_FollowFlushesIdlePartialLine(t *testing.T) {
+	path := writeFile(t, "a\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 1)
+	appendFile(t, path, "par")
+	advanceUntil(t, clock, out, "a\npar")
+	appendFile(t, path, "tial")
+	advanceUntil(t, clock, out, "a\npartial")
+	appendFile(t, path, "\nnext\n")
+	waitForLines(t, out, 3)
+
+	assertion.NoError(t, stop())
+	assertion.Equal(t, out.String(), "a\npartial\nnext\n", "output")
+}
+
+func TestTail_FollowStartsWithPartialLine(t *testing.T) {
+	path := writeFile(t, "a\nb")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.ClockFlag{Clock: clock}))
+	advanceUntil(t, clock, out, "a\nb")
+	appendFile(t, path, "c\n")
+	advanceUntil(t, clock, out, "a\nbc\n")
+
+	assertion.NoError(t, stop())
+	assertion.Equal(t, out.String(), "a\nbc\n", "output")
+}
+
+func TestTail_FollowPartialLineFilteredByFirstPart(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.ExcludePattern("^skip"),
+		command.ClockFlag{Clock: clock}))
+	appendFile(t, path, "skip")
+	for range 3 {
+		clock.Advance(time.Second)
+		time.Sleep(5 * time.Millisecond)
+	}
+	appendFile(t, path, " this\nkeep\n")
+	waitForLines(t, out, 1)
+
+	assertion.NoError(t, stop())
+	assertion.Equal(t, out.String(), "keep\n", "output")
+}
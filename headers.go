@@ -0,0 +1,113 @@
+package command
+
+import (
+	"io"
+	"os"
+)
+
+// headerStdinName names standard input in headers, as GNU tail does.
+const headerStdinName = "standard input"
+
+// showHeaders reports whether n inputs are written in sections under
+// "==> NAME <==" headers: by default when there are several, always with
+// Verbose or AlwaysHeaders, and never with Quiet or SuppressHeaders.
+func (e *engine) showHeaders(n int) bool {
+	if bool(e.Quiet) || bool(e.SuppressHeaders) {
+		return false
+	}
+	return n > 1 || bool(e.Verbose) || bool(e.AlwaysHeaders)
+}
+
+// fileHeader returns the "==> NAME <==" line that starts a section, in a color
+// of its own when output is colored. Every section but the first is set off
+// from the one before by a newline, which also ends a final line that had
+// none.
+func (e *engine) fileHeader(name string) string {
+	header := "==> " + name + " <=="
+	if e.color {
+		header = sgr(e.labelColor(name)) + header + colorReset
+	}
+	if e.sectioned {
+		header = "\n" + header
+	}
+	return header + "\n"
+}
+
+// sectionWriter writes the output of one input in its section, writing the
+// input's header first whenever the output before it came from another
+// input, as it does when follow mode switches between files.
+type sectionWriter struct {
+	engine *engine
+	w      io.Writer
+	name   string
+}
+
+func (e *engine) newSectionWriter(stdout io.Writer, name string) *sectionWriter {
+	return &sectionWriter{engine: e, w: stdout, name: name}
+}
+
+func (s *sectionWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := s.start(); err != nil {
+		return 0, err
+	}
+	return s.w.Write(p)
+}
+
+// start writes the header unless the section is already the current one.
+func (s *sectionWriter) start() error {
+	e := s.engine
+	if e.sectioned && e.section == s.name {
+		return nil
+	}
+	header := e.fileHeader(s.name)
+	e.section, e.sectioned = s.name, true
+	_, err := io.WriteString(s.w, header)
+	return err
+}
+
+// tailSections writes the last records of each file on its own, in its
+// section. The sections share one MaxOutputBytes budget.
+func (e *engine) tailSections(files []*os.File, stdout io.Writer) error {
+	held := e.holdOutput(stdout)
+	return held.release(e.eachSection(files, held))
+}
+
+func (e *engine) eachSection(files []*os.File, stdout io.Writer) error {
+	for _, f := range files {
+		w := e.newSectionWriter(stdout, f.Name())
+		if err := w.start(); err != nil {
+			return err
+		}
+		if err := e.tailFiles([]*os.File{f}, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tailStreamSections is tailSections for inputs read to EOF, such as pipes,
+// standard input and files that must be transcoded.
+func (e *engine) tailStreamSections(readers []io.Reader, stdout io.Writer) error {
+	held := e.holdOutput(stdout)
+	return held.release(e.eachStreamSection(readers, held))
+}
+
+func (e *engine) eachStreamSection(readers []io.Reader, stdout io.Writer) error {
+	for _, r := range readers {
+		name, notice := headerStdinName, stdinName
+		if f, ok := r.(*os.File); ok && f != os.Stdin {
+			name, notice = f.Name(), f.Name()
+		}
+		w := e.newSectionWriter(stdout, name)
+		if err := w.start(); err != nil {
+			return err
+		}
+		if err := e.tailStream(w, notice, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
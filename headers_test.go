@@ -0,0 +1,97 @@
+package command_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+// ==============================================================================
+// Test File Headers
+// ==============================================================================
+
+func TestTail_HeadersForSeveralFiles(t *testing.T) {
+	first := writeFile(t, "a\nb\n")
+	second := writeFile(t, "c\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.LineCount(1))),
+		"==> "+first+" <==\nb\n\n==> "+second+" <==\nc\n", "output")
+}
+
+func TestTail_HeadersVerboseSingleFile(t *testing.T) {
+	path := writeFile(t, "a\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.Verbose)), "==> "+path+" <==\na\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path, command.AlwaysHeaders)), "==> "+path+" <==\na\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(path)), "a\n", "output")
+}
+
+func TestTail_HeadersQuiet(t *testing.T) {
+	first := writeFile(t, "a\n")
+	second := writeFile(t, "b\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.Quiet)), "a\nb\n", "output")
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.Verbose, command.SuppressHeaders)), "a\nb\n", "output")
+}
+
+func TestTail_HeadersAfterPartialLine(t *testing.T) {
+	first := writeFile(t, "a")
+	second := writeFile(t, "b\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second)),
+		"==> "+first+" <==\na\n==> "+second+" <==\nb\n", "output")
+}
+
+func TestTail_HeadersStdin(t *testing.T) {
+	result := run.Command(command.Tail(command.Verbose)).
+		WithStdinLines("a").
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"==> standard input <==", "a"})
+}
+
+func TestTail_HeadersColoredPerFile(t *testing.T) {
+	first := writeFile(t, "a\n")
+	second := writeFile(t, "b\n")
+
+	assertion.Equal(t, runFile(t, command.Tail(first, second, command.ColorAlways)),
+		"\x1b[36m==> "+first+" <=="+reset+"\na\n\n\x1b[35m==> "+second+" <=="+reset+"\nb\n", "output")
+}
+
+func TestTail_HeadersFollowSwitchingFiles(t *testing.T) {
+	first := writeFile(t, "a\n")
+	second := writeFile(t, "b\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(first, second, command.Follow, command.ClockFlag{Clock: clock}))
+	waitForOutput(t, out, "==> "+first+" <==\na\n\n==> "+second+" <==\nb\n")
+	appendFile(t, first, "c\n")
+	waitForOutput(t, out, "==> "+first+" <==\na\n\n==> "+second+" <==\nb\n\n==> "+first+" <==\nc\n")
+	appendFile(t, first, "d\n")
+	waitForOutput(t, out, "==> "+first+" <==\na\n\n==> "+second+" <==\nb\n\n==> "+first+" <==\nc\nd\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_HeadersShareOutputBudget(t *testing.T) {
+	first := writeFile(t, "a\nb\n")
+	second := writeFile(t, "c\n")
+	third := writeFile(t, "d\n")
+	full := runFile(t, command.Tail(first, second, third, command.LineCount(1)))
+
+	out := runFile(t, command.Tail(first, second, third, command.LineCount(1), command.MaxOutputBytes(30)))
+	assertion.Equal(t, out, "d\n", "output")
+
+	// The headers count, and the earliest are dropped with the lines
+	budget := len(full) - 10
+	out = runFile(t, command.Tail(first, second, third, command.LineCount(1), command.MaxOutputBytes(budget)))
+	marker, kept, _ := strings.Cut(out, "\n")
+	assertion.Equal(t, len(out) <= budget, true, "within budget")
+	assertion.Equal(t, strings.HasPrefix(marker, "[... "), true, "marker")
+	assertion.Equal(t, strings.HasSuffix(full, "\n"+kept), true, "latest output kept")
+}
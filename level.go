@@ -0,0 +1,27 @@
+package command
+
+import "strings"
+
+// levelRanks orders common log level names from least to most severe.
+var levelRanks = map[string]int{
+	"trace":    0,
+	"debug":    1,
+	"info":     2,
+	"notice":   3,
+	"warn":     4,
+	"warning":  4,
+	"error":    5,
+	"err":      5,
+	"critical": 6,
+	"crit":     6,
+	"fatal":    6,
+	"alert":    7,
+	"panic":    8,
+	"emerg":    8,
+}
+
+// levelRank returns the severity rank of a log level name.
+func levelRank(level string) (int, bool) {
+	rank, ok := levelRanks[strings.ToLower(level)]
+	return rank, ok
+}
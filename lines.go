@@ -0,0 +1,181 @@
+package command
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultTruncationMarker = "…[+%d bytes]"
+
+// minReadLimit is the least readers hold of a line when MaxLineLength is set.
+const minReadLimit = 64 * 1024
+
+// lineDecoder finishes the lines read from an input. stripCR drops the
+// carriage return of a "\r\n" ending. convert, if set, turns lines into
+// UTF-8. escape, if set, then makes the lines of a binary input safe to
+// print. A zero decoder leaves lines alone.
+//
+// limit, if set, is how much of a line readers hold. The rest of a longer
+// line is only counted as it is read, and the line is truncated by cut as
+// soon as it is decoded.
+type lineDecoder struct {
+	stripCR bool
+	convert func(string) string
+	escape  func(string) string
+	limit   int
+	cut     truncator
+}
+
+func (d lineDecoder) decode(line string) string {
+	if d.convert != nil {
+		line = d.convert(line)
+	}
+	if d.escape != nil {
+		line = d.escape(line)
+	}
+	return line
+}
+
+// finish decodes the held start of a line, of which dropped more bytes were
+// read. cr reports whether the line's last byte read is a carriage return.
+func (d lineDecoder) finish(held []byte, dropped int, cr bool) string {
+	if dropped == 0 {
+		if cr && d.stripCR {
+			held = held[:len(held)-1]
+		}
+		return d.decode(string(held))
+	}
+	ending := ""
+	if cr && (d.stripCR || d.cut.keepCR) {
+		// The dropped carriage return is the line's ending, not its text
+		dropped--
+		if !d.stripCR {
+			ending = "\r"
+		}
+	}
+	return d.cut.truncateLine(d.decode(string(held)), dropped) + ending
+}
+
+// readLimit is how much of a line readers hold when lines are truncated to
+// max bytes: enough for filters to see most lines whole, and for the
+// truncated line to fill max bytes even once decoding has halved it.
+func readLimit(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return max(4*n, minReadLimit)
+}
+
+// truncator cuts output lines longer than max bytes, without splitting a
+// UTF-8 sequence, and appends a marker, in which each "%d" is replaced by
+// the number of bytes cut. When keepCR is set, a line's trailing carriage
+// return is its preserved ending, which is neither counted nor cut. A zero
+// truncator leaves lines alone.
+type truncator struct {
+	max    int
+	marker string
+	keepCR bool
+	marked *regexp.Regexp // the marker, whatever the count
+}
+
+func newTruncator(max int, marker string, keepCR bool) truncator {
+	if marker == "" {
+		marker = defaultTruncationMarker
+	}
+	pattern := strings.ReplaceAll(regexp.QuoteMeta(marker), "%d", `\d+`)
+	return truncator{max: max, marker: marker, keepCR: keepCR, marked: regexp.MustCompile(`(?:` + pattern + `)\z`)}
+}
+
+// truncate truncates each line of a record.
+func (t truncator) truncate(record string) string {
+	if t.max <= 0 {
+		return record
+	}
+	lines := strings.Split(record, "\n")
+	for i, line := range lines {
+		ending := ""
+		if t.keepCR && strings.HasSuffix(line, "\r") {
+			line, ending = line[:len(line)-1], "\r"
+		}
+		lines[i] = t.truncateLine(line, 0) + ending
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateLine truncates a line of which dropped more bytes were read but
+// not held. A line a reader already truncated, which ends in a marker that
+// starts where it was cut, is left as it is.
+func (t truncator) truncateLine(line string, dropped int) string {
+	if len(line) <= t.max && dropped == 0 {
+		return line
+	}
+	if loc := t.marked.FindStringIndex(line); dropped == 0 && loc != nil && loc[0] <= t.max && loc[0] > t.max-utf8.UTFMax {
+		return line
+	}
+	cut := min(t.max, len(line))
+	for cut > 0 && cut < len(line) && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + strings.ReplaceAll(t.marker, "%d", strconv.Itoa(len(line)-cut+dropped))
+}
+
+// lineBuffer assembles a line from fragments, holding no more of it than
+// its decoder's limit.
+type lineBuffer struct {
+	decoder lineDecoder
+	buf     []byte
+	dropped int  // bytes written past the limit
+	cr      bool // whether the last byte written is a carriage return
+}
+
+func (b *lineBuffer) write(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	b.cr = p[len(p)-1] == '\r'
+	if limit := b.decoder.limit; limit > 0 && len(b.buf)+len(p) > limit {
+		n := max(limit-len(b.buf), 0)
+		b.dropped += len(p) - n
+		p = p[:n]
+	}
+	b.buf = append(b.buf, p...)
+}
+
+// pending reports whether part of a line has been written.
+func (b *lineBuffer) pending() bool {
+	return len(b.buf) > 0
+}
+
+// take returns the assembled line and starts a new one.
+func (b *lineBuffer) take() string {
+	line := b.peek()
+	b.buf, b.dropped, b.cr = b.buf[:0], 0, false
+	return line
+}
+
+// peek returns the line assembled so far.
+func (b *lineBuffer) peek() string {
+	return b.decoder.finish(b.buf, b.dropped, b.cr)
+}
+
+// readFrom reads up to the end of the current line. It reports whether the
+// newline was reached and how many bytes it consumed; at EOF the partial
+// line stays in the buffer for a later read to complete.
+func (b *lineBuffer) readFrom(r *bufio.Reader) (bool, int, error) {
+	n := 0
+	for {
+		fragment, err := r.ReadSlice('\n')
+		n += len(fragment)
+		if len(fragment) > 0 && fragment[len(fragment)-1] == '\n' {
+			b.write(fragment[:len(fragment)-1])
+			return true, n, nil
+		}
+		b.write(fragment)
+		if err != bufio.ErrBufferFull {
+			return false, n, err
+		}
+	}
+}
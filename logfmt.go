@@ -0,0 +1,64 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseLogfmt parses a logfmt record such as
+// `level=info msg="user logged in" user=42`. Values may be quoted with Go
+// escapes, and a key without a value is set to "". A record parses when it
+// holds at least one key=value pair.
+func parseLogfmt(record string) (fields, bool) {
+	f := fields{}
+	paired := false
+	for s := strings.TrimLeft(record, " \t"); s != ""; s = strings.TrimLeft(s, " \t") {
+		end := strings.IndexAny(s, " \t=\"")
+		if end < 0 {
+			end = len(s)
+		}
+		key := s[:end]
+		if key == "" {
+			return nil, false
+		}
+		s = s[end:]
+		if !strings.HasPrefix(s, "=") {
+			f[key] = ""
+			continue
+		}
+		value, n, ok := logfmtValue(s[1:])
+		if !ok {
+			return nil, false
+		}
+		f[key], paired = value, true
+		s = s[1+n:]
+	}
+	return f, paired
+}
+
+// logfmtValue reads the value that starts s and returns it with the length
+// read.
+func logfmtValue(s string) (string, int, bool) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		if strings.Contains(s[:end], `"`) {
+			return "", 0, false
+		}
+		return s[:end], end, true
+	}
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", 0, false
+	}
+	value, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", 0, false
+	}
+	if rest := s[len(quoted):]; rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", 0, false
+	}
+	return value, len(quoted), true
+}
@@ -0,0 +1,103 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+var logfmtLog = []string{
+	`time=10:00 level=info msg="server started" port=8080`,
+	`time=10:01 level=warn msg="slow request" user=42 latency=1.5`,
+	`plain text line`,
+	`time=10:02 level=error msg="db \"main\" unreachable" user=7 retry`,
+	`time=10:03 level=debug msg=tick`,
+}
+
+// ==============================================================================
+// Test logfmt Parsing
+// ==============================================================================
+
+func TestTail_LogfmtWhere(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatLogfmt, command.DropUnparsed, command.Where("level>=warn"))).
+		WithStdinLines(logfmtLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{logfmtLog[1], logfmtLog[3]})
+}
+
+func TestTail_LogfmtNumericAndQuoted(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatLogfmt, command.Where("user<10"), command.Where(`msg~"main"`))).
+		WithStdinLines(logfmtLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"plain text line", logfmtLog[3]})
+}
+
+func TestTail_LogfmtFields(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(2), command.FormatLogfmt,
+		command.Field("level"), command.Field("msg"), command.Field("retry"))).
+		WithStdinLines(logfmtLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		`{"level":"error","msg":"db \"main\" unreachable","retry":""}`,
+		`{"level":"debug","msg":"tick"}`,
+	})
+}
+
+func TestTail_LogfmtColumns(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatLogfmt, command.OutputColumns,
+		command.Field("time"), command.Field("level"), command.Field("user"), command.Field("msg"))).
+		WithStdinLines(logfmtLog...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"10:00  info   -   server started",
+		"10:01  warn   42  slow request",
+		"plain text line",
+		`10:02  error  7   db "main" unreachable`,
+		"10:03  debug  -   tick",
+	})
+}
+
+func TestTail_LogfmtColumnsNeedField(t *testing.T) {
+	result := run.Quick(command.Tail(command.FormatLogfmt, command.OutputColumns))
+
+	assertion.ErrorContains(t, result.Err, "OutputColumns needs a Field")
+}
+
+func TestTail_LogfmtUnterminatedQuote(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatLogfmt, command.DropUnparsed)).
+		WithStdinLines(`level=info msg="cut off`, `level=info msg="whole"`, `level=info msg=a"b`).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{`level=info msg="whole"`})
+}
+
+func TestTail_FollowLogfmtColumns(t *testing.T) {
+	path := writeFile(t, logfmtLog[0]+"\n"+logfmtLog[1]+"\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.FormatLogfmt, command.OutputColumns,
+		command.Where("level!=debug"), command.Field("level"), command.Field("msg"), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 2)
+	appendFile(t, path, logfmtLog[4]+"\n"+`level=critical msg="disk full"`+"\n"+`level=info msg=ok`+"\n")
+	lines := waitForLines(t, out, 4)
+	stop()
+
+	assertion.Equal(t, lines, []string{
+		"info  server started",
+		"warn  slow request",
+		"critical  disk full",
+		"info      ok",
+	}, "lines")
+}
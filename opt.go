@@ -1,9 +1,408 @@
 package command
 
+import "time"
+
 type LineCount int
 type ByteCount int
 type StartFromLine int
 
+// SleepInterval is how long follow mode waits between checks for new data.
+type SleepInterval time.Duration
+
+// TimestampLayout prefixes every line read while following with the time it
+// was read, formatted with the given time layout.
+type TimestampLayout string
+
+const (
+	TimestampRFC3339Nano TimestampLayout = time.RFC3339Nano
+	TimestampUnixMillis  TimestampLayout = "unixms"
+	NoTimestamp          TimestampLayout = ""
+)
+
+// ContinuationPattern is a regular expression matching lines that continue
+// the previous record, such as the indented frames of a stack trace. When set,
+// LineCount counts records rather than lines.
+type ContinuationPattern string
+
+const (
+	IndentedContinuation   ContinuationPattern = `^\s`
+	StackTraceContinuation ContinuationPattern = `^(\s|Caused by|\.\.\. \d+ more)`
+)
+
+// IncludePattern keeps only records matching the regular expression. It is
+// applied before counting, so LineCount(20) yields the last 20 matches.
+// Repeat it to keep records matching any of several patterns.
+type IncludePattern string
+
+// ExcludePattern drops records matching the regular expression before
+// counting. Repeat it to drop records matching any of several patterns.
+type ExcludePattern string
+
+// Format parses each record into named fields, which Where conditions,
+// Fields and Template then work on.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatSyslog Format = "syslog" // RFC 5424 or RFC 3164 syslog lines
+	FormatLogfmt Format = "logfmt" // key=value pairs, as in level=info msg="started"
+	FormatRegex  Format = "regex"  // the named groups of the Extract pattern
+	// FormatCSV reads the rows of a CSV file, with fields named by its header
+	// row. LineCount counts rows, which may span lines inside quoted fields,
+	// and the header row is always written first.
+	FormatCSV Format = "csv"
+	// FormatCommonLog and FormatCombinedLog parse the access logs nginx and
+	// Apache write by default; FormatAccessLog parses the AccessLogFormat.
+	FormatCommonLog   Format = "common"
+	FormatCombinedLog Format = "combined"
+	FormatAccessLog   Format = "access"
+	FormatText        Format = ""
+)
+
+// CSVDelimiter separates the fields of FormatCSV rows. It is ',' by
+// default.
+type CSVDelimiter rune
+
+// CSVQuote quotes the fields of FormatCSV rows, which may then hold
+// delimiters, newlines and doubled quotes. It is '"' by default.
+type CSVQuote rune
+
+// Extract parses each record with a regular expression whose named groups,
+// as in `(?P<status>\d{3})`, become its fields. It implies FormatRegex.
+// Unless Fields are selected, OutputTSV, OutputCSV and OutputJSON write the
+// groups in the order they appear.
+type Extract string
+
+// AccessLogFormat parses access log lines laid out by an nginx log_format
+// string. Each $variable becomes a field named without its "$", and the
+// request is also split into method, path and protocol. It implies
+// FormatAccessLog.
+type AccessLogFormat string
+
+const (
+	AccessLogCommon   AccessLogFormat = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent`
+	AccessLogCombined AccessLogFormat = AccessLogCommon + ` "$http_referer" "$http_user_agent"`
+)
+
+// Status keeps only access log requests answered with the status, given
+// as a code such as "404" or a class such as "5xx". Repeat it to keep
+// several.
+type Status string
+
+// SlowerThan keeps only access log requests that took longer than the
+// duration, as logged by $request_time or $upstream_response_time.
+type SlowerThan time.Duration
+
+// PathPrefix keeps only access log requests whose path starts with the
+// prefix. Repeat it to keep several.
+type PathPrefix string
+
+// OutputFormat decides how parsed records are written when no Template is
+// set. OutputColumns pads each column to the widest value written so far,
+// so the columns of later records, such as those that arrive in follow mode,
+// line up with those before them while values fit.
+type OutputFormat string
+
+const (
+	OutputRecord  OutputFormat = ""        // as they were read
+	OutputJSON    OutputFormat = "json"    // all their fields as a JSON object
+	OutputColumns OutputFormat = "columns" // the values of the Fields in aligned columns
+	OutputSummary OutputFormat = "summary" // access log requests as "TIME METHOD PATH STATUS LATENCY BYTES"
+	OutputTSV     OutputFormat = "tsv"     // the values of the Fields separated by tabs, escaping \, tab and newlines
+	OutputCSV     OutputFormat = "csv"     // the values of the Fields as CSV rows, after a header row naming them
+)
+
+// MinSeverity keeps only syslog records at least as severe as the given
+// level, such as "warning". Records without a priority have no severity and
+// are dropped.
+type MinSeverity string
+
+// App keeps only syslog records from an app matching the path.Match
+// pattern, such as "sshd" or "systemd-*". Repeat it to keep several.
+type App string
+
+// Where keeps only records whose field satisfies a condition such as
+// "level>=warn", "service=api" or "path~^/api/". The operators are =, !=, <,
+// <=, >, >= and ~ (regular expression match). Log levels compare by severity
+// and numbers numerically. Repeat it to require several conditions.
+type Where string
+
+// Field selects a field to print in place of the whole record. Repeat it
+// to select several fields.
+type Field string
+
+// Template renders each parsed record with text/template, for example
+// "{{.time}} {{.msg}}".
+type Template string
+
+type DropUnparsedFlag bool
+
+const (
+	DropUnparsed DropUnparsedFlag = true
+	KeepUnparsed DropUnparsedFlag = false
+)
+
+// ReportUnparsedFlag writes the selected records that don't parse in the
+// Format to stderr rather than stdout. They still count toward LineCount,
+// unless DropUnparsed drops them first.
+type ReportUnparsedFlag bool
+
+const (
+	ReportUnparsed   ReportUnparsedFlag = true
+	NoReportUnparsed ReportUnparsedFlag = false
+)
+
+// Since selects the records timestamped within the given duration before
+// now, for example the last 15 minutes. Unless LineCount is also given, all of
+// them are printed.
+type Since time.Duration
+
+// SinceTime selects the records timestamped at or after the given time.
+type SinceTime time.Time
+
+// TimeFormat extracts record timestamps for Since and SinceTime. Pattern is
+// a regular expression whose first group, or whole match, is parsed with the
+// Go time Layout. Timestamps are assumed to increase through the input, which
+// lets seekable files be binary searched for the start of the window. With
+// a ContainerFormat, the runtime's entry timestamps are used instead, and
+// TimeFormat only times entries without one.
+type TimeFormat struct {
+	Pattern string
+	Layout  string
+}
+
+var (
+	// TimeRFC3339 matches a leading RFC 3339 timestamp, optionally in brackets.
+	TimeRFC3339 = TimeFormat{
+		Pattern: `^\[?(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?(?:Z|[+-]\d\d:\d\d))`,
+		Layout:  time.RFC3339,
+	}
+	// TimeSyslog matches the leading "Jan  2 15:04:05" of a syslog line.
+	TimeSyslog = TimeFormat{
+		Pattern: `^([A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d)`,
+		Layout:  time.Stamp,
+	}
+	// TimeCommonLog matches the "[02/Jan/2006:15:04:05 -0700]" of an nginx or
+	// Apache access log line.
+	TimeCommonLog = TimeFormat{
+		Pattern: `\[(\d\d/[A-Z][a-z]{2}/\d{4}:\d\d:\d\d:\d\d [+-]\d{4})\]`,
+		Layout:  "02/Jan/2006:15:04:05 -0700",
+	}
+)
+
+// MaxOutputBytes caps the size of the selected output, truncation marker
+// included. When the last records add up to more, the earliest are dropped
+// and the marker is printed in their place, or left out when it doesn't fit;
+// ErrOutputBudget is returned when not even the last line does. Records that
+// arrive later in follow mode are not counted. Sections under headers and
+// the containers of pods share one budget, headers and labels included.
+type MaxOutputBytes int
+
+// MaxLineLength truncates each output line to at most this many bytes,
+// without splitting a UTF-8 sequence, and appends the TruncationMarker.
+// Lines are truncated only as they are written, so filters, WaitFor and
+// parsing still see the whole line, unless it is longer than 64 KiB and 4
+// times the cap: readers hold only the start of such a line, and it is
+// truncated as it is read.
+type MaxLineLength int
+
+// TruncationMarker is appended to truncated lines. Each "%d" in it is
+// replaced by the number of bytes cut, and the rest is written as it is; the
+// default is "…[+%d bytes]".
+type TruncationMarker string
+
+// BinaryMode decides what happens to an input that looks binary, because it
+// holds NUL bytes or is largely invalid UTF-8. Each file is inspected by its
+// first and last blocks; stdin and pipes are inspected as one input.
+type BinaryMode string
+
+const (
+	BinaryRaw    BinaryMode = ""       // write it unchanged
+	BinaryRefuse BinaryMode = "refuse" // fail with ErrBinary
+	BinaryNotice BinaryMode = "notice" // print "Binary file NAME matches" instead
+	BinaryCaret  BinaryMode = "caret"  // escape non-printables as cat -v does
+	BinaryHex    BinaryMode = "hex"    // escape non-printables as \xNN
+)
+
+// Encoding decides what happens to input that is not valid UTF-8: its bytes
+// pass through unchanged, invalid sequences are replaced with U+FFFD, or the
+// input is transcoded to UTF-8 from a declared encoding. A byte order mark
+// overrides the declared UTF-16 byte order. UTF-16 files are read forward
+// from the start, since their lines can't be found from the end.
+type Encoding string
+
+const (
+	EncodingRaw     Encoding = ""         // pass bytes through unchanged
+	EncodingReplace Encoding = "utf-8"    // replace invalid UTF-8 with U+FFFD
+	EncodingLatin1  Encoding = "latin1"   // transcode from ISO 8859-1
+	EncodingUTF16LE Encoding = "utf-16le" // transcode from little-endian UTF-16
+	EncodingUTF16BE Encoding = "utf-16be" // transcode from big-endian UTF-16
+)
+
+// CRLFMode decides whether "\r\n" ends a line. By default a carriage return
+// is part of the line and written back unchanged, as GNU tail does.
+type CRLFMode string
+
+const (
+	CRLFRaw      CRLFMode = ""         // keep carriage returns in the line
+	CRLFStrip    CRLFMode = "strip"    // end lines at "\r\n" and write "\n"
+	CRLFPreserve CRLFMode = "preserve" // end lines at "\r\n" but write it back
+)
+
+// RecordFlushTimeout is how long follow mode waits for more continuation
+// lines before emitting a record.
+type RecordFlushTimeout time.Duration
+
+// PartialLines decides when follow mode writes a line whose newline has not
+// arrived yet, such as a progress line. Once part of a line is written, the
+// rest follows as it arrives.
+type PartialLines string
+
+const (
+	PartialIdle      PartialLines = ""          // after PartialLineTimeout without new bytes
+	PartialImmediate PartialLines = "immediate" // as soon as it is read, as GNU tail does
+	PartialComplete  PartialLines = "complete"  // only once its newline arrives
+)
+
+// PartialLineTimeout is how long PartialIdle waits for more of a line.
+type PartialLineTimeout time.Duration
+
+// WaitFor follows the inputs until a record matches the pattern, then ends
+// successfully. It implies Follow; use Wait to get the matching record.
+type WaitFor string
+
+// WaitTimeout ends a WaitFor with ErrWaitTimeout when no record has matched
+// in time. It is measured with the follow mode Clock.
+type WaitTimeout time.Duration
+
+// WaitExistingFlag decides whether WaitFor also matches the records
+// already in the inputs, or only those that arrive while following.
+type WaitExistingFlag bool
+
+const (
+	WaitScanExisting WaitExistingFlag = true
+	WaitNewRecords   WaitExistingFlag = false
+)
+
+// FollowDuration ends follow mode with ErrMaxDuration once it has run this
+// long.
+type FollowDuration time.Duration
+
+// FollowIdleTimeout ends follow mode with ErrIdleTimeout once no input has
+// arrived for this long.
+type FollowIdleTimeout time.Duration
+
+// FollowMaxLines ends follow mode with ErrMaxLines once it has written this
+// many new records, not counting the last records written when it starts.
+type FollowMaxLines int
+
+// ContainerFormat unwraps the entries of a container runtime's log into the
+// lines the container wrote, joining the lines the runtime split into
+// partial entries. LineCount counts the unwrapped lines, and lines that are
+// not in the format pass through unchanged.
+type ContainerFormat string
+
+const (
+	ContainerNone   ContainerFormat = ""       // read lines as they are
+	ContainerDocker ContainerFormat = "docker" // Docker's json-file log driver
+	ContainerCRI    ContainerFormat = "cri"    // CRI runtimes such as containerd and CRI-O
+)
+
+// ContainerStream keeps only the lines a container wrote to one stream.
+type ContainerStream string
+
+const (
+	StreamAll    ContainerStream = ""
+	StreamStdout ContainerStream = "stdout"
+	StreamStderr ContainerStream = "stderr"
+)
+
+// Pod tails the logs the kubelet keeps on the node for a container, given
+// as "namespace/pod/container", or "namespace/pod" for all of the pod's
+// containers. Each part may be a path.Match pattern such as "api-*". The
+// files of a container's restarts are read as one log, lines are labelled
+// "[namespace/pod/container] ", and ContainerCRI is implied. Repeat it to
+// tail several.
+type Pod string
+
+// PodLogDir is where the kubelet keeps pod logs, /var/log/pods by default.
+type PodLogDir string
+
+// Redact hides the secrets and personal data a detector finds in each
+// record before it is rendered and written, in follow mode too. Repeat it to
+// use several.
+type Redact string
+
+const (
+	RedactJWT        Redact = "jwt"         // JSON Web Tokens
+	RedactBearer     Redact = "bearer"      // the TOKEN of "Bearer TOKEN"
+	RedactAWSKey     Redact = "aws-key"     // AWS access key IDs, and secret keys assigned to aws_secret_access_key
+	RedactEmail      Redact = "email"       // email addresses
+	RedactIPv4       Redact = "ipv4"        // IPv4 addresses
+	RedactIPv6       Redact = "ipv6"        // IPv6 addresses
+	RedactCreditCard Redact = "credit-card" // card numbers that pass the Luhn check
+)
+
+// RedactPattern hides the text matching the regular expression, or only
+// what its first group matches if it has one, as in `password=(\S+)`.
+// Repeat it to hide several.
+type RedactPattern string
+
+// RedactMask decides what hidden text is replaced with.
+type RedactMask string
+
+const (
+	MaskReplacement RedactMask = ""     // the RedactReplacement
+	MaskHash        RedactMask = "hash" // "[KIND:HASH]", the same wherever the text is the same
+)
+
+// RedactReplacement replaces hidden text under MaskReplacement. It is
+// "[REDACTED]" by default.
+type RedactReplacement string
+
+// RedactHashKey keys the MaskHash hashes, so that they stay the same from
+// one run to the next. Without it each run picks a random key, which keeps
+// hashes from being reversed by hashing guesses, but they only match within
+// the run.
+type RedactHashKey string
+
+// ColorMode decides whether output is colored: each record in the color of
+// its level, Highlight matches in theirs, and pod labels each in a color of
+// its own.
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = ""       // when stdout is a terminal and NO_COLOR is not set
+	ColorAlways ColorMode = "always" // even when NO_COLOR is set
+	ColorNever  ColorMode = "never"
+)
+
+// Color is an ANSI SGR parameter, such as "31" for red or "1;35" for bold
+// magenta.
+type Color string
+
+const (
+	ColorRed     Color = "31"
+	ColorGreen   Color = "32"
+	ColorYellow  Color = "33"
+	ColorBlue    Color = "34"
+	ColorMagenta Color = "35"
+	ColorCyan    Color = "36"
+	ColorBold    Color = "1"
+	ColorDim     Color = "2"
+)
+
+// Highlight colors the text matching the regular expression Pattern when
+// output is colored. Repeat it to add rules; where matches overlap, the one
+// that starts first wins, then the earlier rule.
+type Highlight struct {
+	Pattern string
+	Color   Color
+}
+
+// ClockFlag replaces the wall clock used by follow mode.
+type ClockFlag struct{ Clock }
+
 type FollowFlag bool
 
 const (
@@ -18,6 +417,8 @@ const (
 	NoFollowRetry FollowRetryFlag = false
 )
 
+// QuietFlag never writes "==> NAME <==" headers, and reads several inputs
+// as one. It wins over VerboseFlag.
 type QuietFlag bool
 
 const (
@@ -25,6 +426,8 @@ const (
 	NoQuiet QuietFlag = false
 )
 
+// VerboseFlag writes a "==> NAME <==" header before each input, even when
+// there is only one. Without it, headers are written for several inputs.
 type VerboseFlag bool
 
 const (
@@ -32,6 +435,7 @@ const (
 	NoVerbose VerboseFlag = false
 )
 
+// SuppressHeadersFlag is QuietFlag.
 type SuppressHeadersFlag bool
 
 const (
@@ -39,6 +443,7 @@ const (
 	NoSuppressHeaders SuppressHeadersFlag = false
 )
 
+// AlwaysHeadersFlag is VerboseFlag.
 type AlwaysHeadersFlag bool
 
 const (
@@ -47,15 +452,66 @@ const (
 )
 
 type flags struct {
-	Lines           LineCount
-	Bytes           ByteCount
-	StartFromLine   StartFromLine
-	Follow          FollowFlag
-	FollowRetry     FollowRetryFlag
-	Quiet           QuietFlag
-	Verbose         VerboseFlag
-	SuppressHeaders SuppressHeadersFlag
-	AlwaysHeaders   AlwaysHeadersFlag
+	Lines              LineCount
+	Bytes              ByteCount
+	StartFromLine      StartFromLine
+	Follow             FollowFlag
+	FollowRetry        FollowRetryFlag
+	Quiet              QuietFlag
+	Verbose            VerboseFlag
+	SuppressHeaders    SuppressHeadersFlag
+	AlwaysHeaders      AlwaysHeadersFlag
+	SleepInterval      SleepInterval
+	Timestamps         TimestampLayout
+	Clock              Clock
+	Continuation       ContinuationPattern
+	RecordFlushTimeout RecordFlushTimeout
+	PartialLines       PartialLines
+	PartialLineTimeout PartialLineTimeout
+	Include            []IncludePattern
+	Exclude            []ExcludePattern
+	Format             Format
+	Extract            Extract
+	CSVDelimiter       CSVDelimiter
+	CSVQuote           CSVQuote
+	AccessLog          AccessLogFormat
+	Statuses           []Status
+	SlowerThan         SlowerThan
+	PathPrefixes       []PathPrefix
+	Where              []Where
+	Fields             []Field
+	Template           Template
+	Output             OutputFormat
+	MinSeverity        MinSeverity
+	Apps               []App
+	DropUnparsed       DropUnparsedFlag
+	ReportUnparsed     ReportUnparsedFlag
+	Since              Since
+	SinceTime          SinceTime
+	TimeFormat         TimeFormat
+	MaxOutputBytes     MaxOutputBytes
+	MaxLineLength      MaxLineLength
+	TruncationMarker   TruncationMarker
+	Binary             BinaryMode
+	Encoding           Encoding
+	CRLF               CRLFMode
+	WaitFor            WaitFor
+	WaitTimeout        WaitTimeout
+	WaitScanExisting   WaitExistingFlag
+	FollowDuration     FollowDuration
+	FollowIdle         FollowIdleTimeout
+	FollowMaxLines     FollowMaxLines
+	Container          ContainerFormat
+	Stream             ContainerStream
+	Pods               []Pod
+	PodLogDir          PodLogDir
+	Color              ColorMode
+	Highlights         []Highlight
+	Redacts            []Redact
+	Redactions         []RedactPattern
+	RedactMask         RedactMask
+	RedactReplacement  RedactReplacement
+	RedactHashKey      RedactHashKey
 }
 
 func (l LineCount) Configure(flags *flags)           { flags.Lines = l }
@@ -67,3 +523,54 @@ func (q QuietFlag) Configure(flags *flags)           { flags.Quiet = q }
 func (v VerboseFlag) Configure(flags *flags)         { flags.Verbose = v }
 func (s SuppressHeadersFlag) Configure(flags *flags) { flags.SuppressHeaders = s }
 func (a AlwaysHeadersFlag) Configure(flags *flags)   { flags.AlwaysHeaders = a }
+func (s SleepInterval) Configure(flags *flags)       { flags.SleepInterval = s }
+func (t TimestampLayout) Configure(flags *flags)     { flags.Timestamps = t }
+func (c ClockFlag) Configure(flags *flags)           { flags.Clock = c.Clock }
+func (c ContinuationPattern) Configure(flags *flags) { flags.Continuation = c }
+func (r RecordFlushTimeout) Configure(flags *flags)  { flags.RecordFlushTimeout = r }
+func (p PartialLines) Configure(flags *flags)        { flags.PartialLines = p }
+func (p PartialLineTimeout) Configure(flags *flags)  { flags.PartialLineTimeout = p }
+func (i IncludePattern) Configure(flags *flags)      { flags.Include = append(flags.Include, i) }
+func (x ExcludePattern) Configure(flags *flags)      { flags.Exclude = append(flags.Exclude, x) }
+func (f Format) Configure(flags *flags)              { flags.Format = f }
+func (x Extract) Configure(flags *flags)             { flags.Extract = x }
+func (c CSVDelimiter) Configure(flags *flags)        { flags.CSVDelimiter = c }
+func (c CSVQuote) Configure(flags *flags)            { flags.CSVQuote = c }
+func (a AccessLogFormat) Configure(flags *flags)     { flags.AccessLog = a }
+func (s Status) Configure(flags *flags)              { flags.Statuses = append(flags.Statuses, s) }
+func (s SlowerThan) Configure(flags *flags)          { flags.SlowerThan = s }
+func (p PathPrefix) Configure(flags *flags)          { flags.PathPrefixes = append(flags.PathPrefixes, p) }
+func (w Where) Configure(flags *flags)               { flags.Where = append(flags.Where, w) }
+func (f Field) Configure(flags *flags)               { flags.Fields = append(flags.Fields, f) }
+func (t Template) Configure(flags *flags)            { flags.Template = t }
+func (o OutputFormat) Configure(flags *flags)        { flags.Output = o }
+func (m MinSeverity) Configure(flags *flags)         { flags.MinSeverity = m }
+func (a App) Configure(flags *flags)                 { flags.Apps = append(flags.Apps, a) }
+func (d DropUnparsedFlag) Configure(flags *flags)    { flags.DropUnparsed = d }
+func (r ReportUnparsedFlag) Configure(flags *flags)  { flags.ReportUnparsed = r }
+func (s Since) Configure(flags *flags)               { flags.Since = s }
+func (s SinceTime) Configure(flags *flags)           { flags.SinceTime = s }
+func (t TimeFormat) Configure(flags *flags)          { flags.TimeFormat = t }
+func (m MaxOutputBytes) Configure(flags *flags)      { flags.MaxOutputBytes = m }
+func (m MaxLineLength) Configure(flags *flags)       { flags.MaxLineLength = m }
+func (t TruncationMarker) Configure(flags *flags)    { flags.TruncationMarker = t }
+func (b BinaryMode) Configure(flags *flags)          { flags.Binary = b }
+func (e Encoding) Configure(flags *flags)            { flags.Encoding = e }
+func (c CRLFMode) Configure(flags *flags)            { flags.CRLF = c }
+func (w WaitFor) Configure(flags *flags)             { flags.WaitFor = w }
+func (w WaitTimeout) Configure(flags *flags)         { flags.WaitTimeout = w }
+func (w WaitExistingFlag) Configure(flags *flags)    { flags.WaitScanExisting = w }
+func (f FollowDuration) Configure(flags *flags)      { flags.FollowDuration = f }
+func (f FollowIdleTimeout) Configure(flags *flags)   { flags.FollowIdle = f }
+func (f FollowMaxLines) Configure(flags *flags)      { flags.FollowMaxLines = f }
+func (c ContainerFormat) Configure(flags *flags)     { flags.Container = c }
+func (c ContainerStream) Configure(flags *flags)     { flags.Stream = c }
+func (p Pod) Configure(flags *flags)                 { flags.Pods = append(flags.Pods, p) }
+func (p PodLogDir) Configure(flags *flags)           { flags.PodLogDir = p }
+func (c ColorMode) Configure(flags *flags)           { flags.Color = c }
+func (h Highlight) Configure(flags *flags)           { flags.Highlights = append(flags.Highlights, h) }
+func (r Redact) Configure(flags *flags)              { flags.Redacts = append(flags.Redacts, r) }
+func (r RedactPattern) Configure(flags *flags)       { flags.Redactions = append(flags.Redactions, r) }
+func (r RedactMask) Configure(flags *flags)          { flags.RedactMask = r }
+func (r RedactReplacement) Configure(flags *flags)   { flags.RedactReplacement = r }
+func (r RedactHashKey) Configure(flags *flags)       { flags.RedactHashKey = r }
@@ -0,0 +1,282 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrOutputBudget is returned when MaxOutputBytes leaves room neither for
+// the latest line nor for the marker of the lines dropped.
+var ErrOutputBudget = errors.New("MaxOutputBytes has no room for a line or the truncation marker")
+
+// write renders the selected records and writes them after the header row,
+// if there is one and it is not yet written. When they add up to
+// more than MaxOutputBytes, the earliest are dropped and a marker saying how
+// much was left out is written in their place. When partial is set the last
+// record came from a final line without a newline, and it is written without
+// one unless it is rendered from fields.
+func (e *engine) write(stdout io.Writer, records []string, partial bool) error {
+	return e.writeNoticed(stdout, records, nil, partial)
+}
+
+// writeNoticed is write for records among which notices are written as they
+// are, each before the record at its index or after the last at
+// len(records). A final line followed by a notice is written with its
+// newline.
+func (e *engine) writeNoticed(stdout io.Writer, records []string, notices map[int]string, partial bool) error {
+	lines, err := e.format(records, notices)
+	if err != nil {
+		return err
+	}
+	partial = partial && e.Format == FormatText && notices[len(records)] == ""
+	marker := ""
+	if !e.held {
+		if lines, marker, err = e.budget(lines, partial); err != nil {
+			return err
+		}
+	}
+	if err := e.writeHeader(stdout, true); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(stdout, marker); err != nil {
+		return err
+	}
+	return writeLines(stdout, lines, partial)
+}
+
+// emit renders records that arrived while following and writes them, each
+// prefixed with its arrival time when Timestamps is set. When partial is set
+// the last one is a stream's final line, which had no newline.
+func (e *engine) emit(stdout io.Writer, now time.Time, records []string, partial bool) error {
+	lines, err := e.format(records, nil)
+	if err != nil {
+		return err
+	}
+	for i, line := range lines {
+		lines[i] = e.stamp(now, line)
+	}
+	if err := e.writeHeader(stdout, len(lines) > 0); err != nil {
+		return err
+	}
+	return writeLines(stdout, lines, partial && e.Format == FormatText)
+}
+
+// writeHeader writes the header row before the first lines written.
+func (e *engine) writeHeader(stdout io.Writer, writing bool) error {
+	if !writing {
+		return nil
+	}
+	header, ok := e.header()
+	if !ok {
+		return nil
+	}
+	_, err := fmt.Fprintln(stdout, header)
+	return err
+}
+
+// header returns the header row the first time it is asked for: the row
+// naming the OutputCSV columns, or the header row of FormatCSV input written
+// as it was read.
+func (e *engine) header() (string, bool) {
+	if e.headed {
+		return "", false
+	}
+	var header string
+	switch {
+	case e.Output == OutputCSV:
+		names := make([]string, len(e.rowFields()))
+		for i, name := range e.rowFields() {
+			names[i] = string(name)
+		}
+		header = e.joinRow(names)
+	case e.Format == FormatCSV && e.csvColumns != nil && e.rawOutput():
+		header = e.csvHeader
+	default:
+		return "", false
+	}
+	e.headed = true
+	return header, true
+}
+
+// format redacts records and renders them for output, widening the
+// OutputColumns columns to fit them first. Records ReportUnparsed reports
+// are left out. Colored output colors each by its record's level. The
+// notices writeNoticed places are added as they are.
+func (e *engine) format(records []string, notices map[int]string) ([]string, error) {
+	records = e.redactAll(records)
+	e.widen(records)
+	lines := make([]string, 0, len(records)+len(notices))
+	notice := func(i int) {
+		if notices[i] != "" {
+			lines = append(lines, strings.TrimSuffix(notices[i], "\n"))
+		}
+	}
+	for i, record := range records {
+		notice(i)
+		reported, err := e.reportUnparsed(record)
+		if err != nil {
+			return nil, err
+		}
+		if reported {
+			continue
+		}
+		line, ok, err := e.renderOrReport(record)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		line = e.truncator.truncate(line)
+		if e.color {
+			line = e.colorize(record, line)
+		}
+		lines = append(lines, line)
+	}
+	notice(len(records))
+	return lines, nil
+}
+
+// stamp prefixes a record that arrived while following with its arrival time
+// when Timestamps is set. The last records written when following starts
+// were already in the input, so they are not stamped.
+func (e *engine) stamp(now time.Time, record string) string {
+	switch e.Timestamps {
+	case NoTimestamp:
+		return record
+	case TimestampUnixMillis:
+		return strconv.FormatInt(now.UnixMilli(), 10) + " " + record
+	default:
+		return now.Format(string(e.Timestamps)) + " " + record
+	}
+}
+
+// budget keeps the latest lines that fit in MaxOutputBytes together with the
+// marker for the lines it drops, and returns them with the marker, if any.
+// Only bytes that are written count: each line's newline, except after a
+// partial final line, and the marker. When the marker doesn't fit beside
+// any line, the latest lines that do are kept without it, so that output is
+// never empty only for lack of room: ErrOutputBudget is returned when not
+// even the last line fits.
+func (e *engine) budget(lines []string, partial bool) ([]string, string, error) {
+	size := func(i int) int {
+		if partial && i == len(lines)-1 {
+			return len(lines[i])
+		}
+		return len(lines[i]) + 1
+	}
+	total := 0
+	for i := range lines {
+		total += size(i)
+	}
+	if e.MaxOutputBytes <= 0 || total <= int(e.MaxOutputBytes) {
+		return lines, "", nil
+	}
+	// Each line dropped shrinks the output but can lengthen the marker
+	dropped, droppedBytes := 0, 0
+	fits := -1 // the index of the first line that fits without the marker
+	for i := range lines {
+		dropped += strings.Count(lines[i], "\n") + 1
+		droppedBytes += size(i)
+		marker := fmt.Sprintf("[... %s (%s) truncated ...]\n", counted(dropped, "line"), counted(droppedBytes, "byte"))
+		if i+1 == len(lines) && fits >= 0 {
+			break // rather than the marker alone, the lines that fit
+		}
+		if total-droppedBytes+len(marker) <= int(e.MaxOutputBytes) {
+			return lines[i+1:], marker, nil
+		}
+		if fits < 0 && total-droppedBytes <= int(e.MaxOutputBytes) && i+1 < len(lines) {
+			fits = i + 1
+		}
+	}
+	if fits < 0 {
+		return nil, "", ErrOutputBudget
+	}
+	return lines[fits:], "", nil
+}
+
+// counted returns n followed by noun, in the plural unless n is 1.
+func counted(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// heldOutput holds the output of several inputs, such as files in sections
+// or the containers of pods, so that they share one MaxOutputBytes budget:
+// release budgets it as a whole, headers and labels included, and writes
+// it. Output written after release, as in follow mode, passes straight
+// through.
+type heldOutput struct {
+	engine *engine
+	w      io.Writer
+	buf    bytes.Buffer
+}
+
+// holdOutput holds the output written to stdout until release, when there
+// is a budget to keep.
+func (e *engine) holdOutput(stdout io.Writer) *heldOutput {
+	e.held = e.MaxOutputBytes > 0
+	return &heldOutput{engine: e, w: stdout}
+}
+
+func (h *heldOutput) Write(p []byte) (int, error) {
+	if h.engine.held {
+		return h.buf.Write(p)
+	}
+	return h.w.Write(p)
+}
+
+// release writes the output held so far within the budget, and lets later
+// output through. err, if set, is the error that stopped the output, and is
+// returned in preference to release's own.
+func (h *heldOutput) release(err error) error {
+	e := h.engine
+	if !e.held {
+		return err
+	}
+	e.held = false
+	out := h.buf.String()
+	h.buf.Reset()
+	if out == "" {
+		return err
+	}
+	partial := !strings.HasSuffix(out, "\n")
+	lines, marker, berr := e.budget(strings.Split(strings.TrimSuffix(out, "\n"), "\n"), partial)
+	if berr == nil {
+		if _, berr = io.WriteString(h.w, marker); berr == nil {
+			berr = writeLines(h.w, lines, partial)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return berr
+}
+
+// writeLines writes each line with a newline, except the last when partial
+// is set.
+func writeLines(stdout io.Writer, lines []string, partial bool) error {
+	for i, line := range lines {
+		if partial && i == len(lines)-1 {
+			_, err := io.WriteString(stdout, line)
+			return err
+		}
+		if _, err := fmt.Fprintln(stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOutput sets where output goes, and decides whether it is colored.
+func (e *engine) setOutput(stdout, stderr io.Writer) {
+	e.stderr = stderr
+	e.setColor(stdout)
+}
@@ -0,0 +1,409 @@
+package command
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPodLogDir = "/var/log/pods"
+
+// ErrNoPodLogs is returned when no container on the node matches the Pods.
+var ErrNoPodLogs = errors.New("no pod logs found")
+
+// podSelector is a compiled Pod.
+type podSelector struct {
+	namespace string
+	pod       string
+	container string
+}
+
+func compilePod(pod Pod) (podSelector, error) {
+	parts := strings.Split(string(pod), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return podSelector{}, fmt.Errorf("invalid pod %q: want namespace/pod/container", string(pod))
+	}
+	s := podSelector{namespace: parts[0], pod: parts[1], container: "*"}
+	if len(parts) == 3 {
+		s.container = parts[2]
+	}
+	for _, pattern := range []string{s.namespace, s.pod, s.container} {
+		if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+			return podSelector{}, fmt.Errorf("invalid pod %q", string(pod))
+		}
+	}
+	return s, nil
+}
+
+func (s podSelector) match(namespace, pod, container string) bool {
+	return matchName(s.namespace, namespace) && matchName(s.pod, pod) && matchName(s.container, container)
+}
+
+func matchName(pattern, name string) bool {
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+func (e *engine) podLogDir() string {
+	if e.PodLogDir == "" {
+		return defaultPodLogDir
+	}
+	return string(e.PodLogDir)
+}
+
+// containerLog is the log a Kubernetes container keeps on the node: a file
+// for each time it started, oldest first.
+type containerLog struct {
+	label string // "namespace/pod/container"
+	files []string
+}
+
+// logFile is one of the files of a containerLog.
+type logFile struct {
+	path     string
+	restarts int
+	modified time.Time
+}
+
+// podLogs finds the logs of the containers the Pods select. The kubelet
+// keeps them in PodLogDir as NAMESPACE_POD_UID/CONTAINER/N.log, where N
+// counts the container's restarts, and a pod created again under the same
+// name gets a new UID.
+func (e *engine) podLogs() ([]containerLog, error) {
+	root := e.podLogDir()
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string][]logFile{}
+	for _, dir := range dirs {
+		namespace, rest, _ := strings.Cut(dir.Name(), "_")
+		pod, _, ok := strings.Cut(rest, "_")
+		if !dir.IsDir() || !ok {
+			continue
+		}
+		containers, err := readDir(filepath.Join(root, dir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			if !container.IsDir() || !e.podSelected(namespace, pod, container.Name()) {
+				continue
+			}
+			files, err := restartLogs(filepath.Join(root, dir.Name(), container.Name()))
+			if err != nil {
+				return nil, err
+			}
+			key := namespace + "/" + pod + "/" + container.Name()
+			found[key] = append(found[key], files...)
+		}
+	}
+
+	var logs []containerLog
+	for _, key := range slices.Sorted(maps.Keys(found)) {
+		files := found[key]
+		if len(files) == 0 {
+			continue
+		}
+		slices.SortFunc(files, func(a, b logFile) int {
+			return cmp.Or(a.modified.Compare(b.modified), cmp.Compare(a.restarts, b.restarts))
+		})
+		log := containerLog{label: key}
+		for _, f := range files {
+			log.files = append(log.files, f.path)
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func (e *engine) podSelected(namespace, pod, container string) bool {
+	for _, s := range e.pods {
+		if s.match(namespace, pod, container) {
+			return true
+		}
+	}
+	return false
+}
+
+// restartLogs returns the N.log files of a container's log directory.
+// Rotated files, which have a suffix, are left out.
+func restartLogs(dir string) ([]logFile, error) {
+	entries, err := readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []logFile
+	for _, entry := range entries {
+		n, ok := strings.CutSuffix(entry.Name(), ".log")
+		restarts, err := strconv.Atoi(n)
+		if !ok || err != nil || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, logFile{path: filepath.Join(dir, entry.Name()), restarts: restarts, modified: info.ModTime()})
+	}
+	return files, nil
+}
+
+// readDir is os.ReadDir for a directory the kubelet may remove at any time.
+func readDir(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// tailPods writes the last records of each container the Pods select, its
+// files read as one input, with each line labelled with the pod and
+// container. The containers share one MaxOutputBytes budget.
+func (e *engine) tailPods(stdout io.Writer) error {
+	logs, err := e.podLogs()
+	if err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		return fmt.Errorf("%w for %s", ErrNoPodLogs, e.podNames())
+	}
+	held := e.holdOutput(stdout)
+	return held.release(e.eachContainer(logs, held))
+}
+
+func (e *engine) eachContainer(logs []containerLog, stdout io.Writer) error {
+	for _, log := range logs {
+		if err := e.tailContainer(stdout, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *engine) tailContainer(stdout io.Writer, log containerLog) error {
+	files, err := openFiles(log.files)
+	defer closeFiles(files)
+	if err != nil {
+		return err
+	}
+	w := &labelWriter{w: stdout, prefix: e.labelPrefix(log.label)}
+	if err := e.tailFiles(files, w); err != nil {
+		return err
+	}
+	return w.endLine()
+}
+
+// followPods follows the containers the Pods select as follow does files.
+// Each container is followed from the end of its latest file, and the file
+// it starts when it restarts, the file the kubelet creates in place of one
+// it rotates, or a container that appears, is followed from its start. The
+// last records of the containers share one MaxOutputBytes budget.
+func (e *engine) followPods(ctx context.Context, stdout io.Writer) error {
+	began := e.clock.Now()
+	logs, err := e.podLogs()
+	if err != nil {
+		return err
+	}
+	held := e.holdOutput(stdout)
+	w := &podWatcher{engine: e, stdout: held, followed: map[string]os.FileInfo{}, writers: map[string]*labelWriter{}}
+	defer w.close()
+	followers, err := w.startAll(logs)
+	if err := held.release(err); err != nil || e.done {
+		return err
+	}
+	return e.poll(ctx, began, followers, w.discover)
+}
+
+// podWatcher tracks the container log files followPods follows.
+type podWatcher struct {
+	engine   *engine
+	stdout   io.Writer
+	files    []*os.File
+	followed map[string]os.FileInfo  // the file opened at each path
+	writers  map[string]*labelWriter // by label
+}
+
+// startAll starts each container, and returns followers for those it
+// follows. It stops at a record that matches WaitFor.
+func (w *podWatcher) startAll(logs []containerLog) ([]*lineFollower, error) {
+	var followers []*lineFollower
+	for _, log := range logs {
+		follower, err := w.start(log)
+		if err != nil || w.engine.done {
+			return nil, err
+		}
+		if follower != nil {
+			followers = append(followers, follower)
+		}
+	}
+	return followers, nil
+}
+
+// start writes the last records of a container and returns a follower for
+// its latest file.
+func (w *podWatcher) start(log containerLog) (*lineFollower, error) {
+	files, err := openFiles(log.files)
+	w.files = append(w.files, files...)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := w.track(f); err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	latest := files[len(files)-1]
+	return w.engine.followFiles(w.writer(log.label), files[:len(files)-1], latest)
+}
+
+// discover returns followers for the container log files that have appeared
+// since the last call. Files are told apart by identity rather than path,
+// since the kubelet rotates a log by renaming it and creating a new file of
+// the same name. The follower of the renamed file still reads what was
+// written to it before the rename.
+func (w *podWatcher) discover() ([]*lineFollower, error) {
+	logs, err := w.engine.podLogs()
+	if err != nil {
+		return nil, err
+	}
+	var followers []*lineFollower
+	for _, log := range logs {
+		for _, path := range log.files {
+			if seen, ok := w.followed[path]; ok {
+				info, err := os.Stat(path)
+				if err != nil || os.SameFile(seen, info) {
+					continue
+				}
+			}
+			f, err := os.Open(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			w.files = append(w.files, f)
+			if err := w.track(f); err != nil {
+				return nil, err
+			}
+			followers = append(followers, w.engine.newFollower(w.writer(log.label), f, w.engine.readDecoder))
+		}
+	}
+	return followers, nil
+}
+
+// track records f as the file followed at its path.
+func (w *podWatcher) track(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	w.followed[f.Name()] = info
+	return nil
+}
+
+// writer returns the writer labelling the lines of a container.
+func (w *podWatcher) writer(label string) *labelWriter {
+	if lw, ok := w.writers[label]; ok {
+		return lw
+	}
+	lw := &labelWriter{w: w.stdout, prefix: w.engine.labelPrefix(label)}
+	w.writers[label] = lw
+	return lw
+}
+
+func (w *podWatcher) close() {
+	closeFiles(w.files)
+}
+
+// podNames lists the Pods for messages.
+func (e *engine) podNames() string {
+	names := make([]string, len(e.Pods))
+	for i, pod := range e.Pods {
+		names[i] = strconv.Quote(string(pod))
+	}
+	return strings.Join(names, ", ")
+}
+
+// openFiles opens the named files, skipping those the kubelet has removed
+// since they were listed. It returns those it opened even when it fails,
+// for the caller to close.
+func openFiles(paths []string) ([]*os.File, error) {
+	var files []*os.File
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return files, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// labelWriter prefixes each line written through it with "[LABEL] ".
+type labelWriter struct {
+	w       io.Writer
+	prefix  string
+	midLine bool // whether the last byte written did not end a line
+}
+
+func (l *labelWriter) Write(p []byte) (int, error) {
+	var b []byte
+	for rest := p; len(rest) > 0; {
+		if !l.midLine {
+			b = append(b, l.prefix...)
+		}
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			b = append(b, rest...)
+			l.midLine = true
+			break
+		}
+		b = append(b, rest[:i+1]...)
+		rest = rest[i+1:]
+		l.midLine = false
+	}
+	if _, err := l.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// endLine ends a line left without its newline, so that the next label
+// starts a line of its own.
+func (l *labelWriter) endLine() error {
+	if !l.midLine {
+		return nil
+	}
+	l.midLine = false
+	_, err := io.WriteString(l.w, "\n")
+	return err
+}
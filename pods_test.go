@@ -0,0 +1,227 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+// writePodLog writes the log of a container's restart under root, as the
+// kubelet lays it out, modified at the given time, and returns its path.
+func writePodLog(t *testing.T, root, pod, container string, restart int, modified time.Time, lines ...string) string {
+	t.Helper()
+	dir := filepath.Join(root, pod, container)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var content string
+	for _, line := range lines {
+		content += "2026-10-17T10:00:00.000000000Z " + line + "\n"
+	}
+	path := filepath.Join(dir, strconv.Itoa(restart)+".log")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// podLayout builds a node's pod log directory: the api pod's app container
+// has restarted once, and it has a sidecar.
+func podLayout(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	writePodLog(t, root, "default_api-7d9f_1111", "app", 0, start, "stdout F first run 1", "stdout F first run 2")
+	writePodLog(t, root, "default_api-7d9f_1111", "app", 1, start.Add(time.Minute),
+		"stdout F second run 1", "stderr F second run oops", "stdout P second ", "stdout F run 2")
+	writePodLog(t, root, "default_api-7d9f_1111", "proxy", 0, start, "stdout F proxy up")
+	writePodLog(t, root, "kube-system_dns-1_2222", "dns", 0, start, "stdout F dns up")
+	os.WriteFile(filepath.Join(root, "default_api-7d9f_1111", "app", "0.log.20261017-100000"), []byte("rotated\n"), 0o644)
+	return root
+}
+
+// ==============================================================================
+// Test Kubernetes Pod Logs
+// ==============================================================================
+
+func TestTail_PodAcrossRestarts(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("default/api-7d9f/app"), command.PodLogDir(root), command.LineCount(4)))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"[default/api-7d9f/app] first run 2",
+		"[default/api-7d9f/app] second run 1",
+		"[default/api-7d9f/app] second run oops",
+		"[default/api-7d9f/app] second run 2",
+	})
+}
+
+func TestTail_PodAllContainers(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("default/api-*"), command.PodLogDir(root), command.LineCount(1)))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[default/api-7d9f/app] second run 2", "[default/api-7d9f/proxy] proxy up"})
+}
+
+func TestTail_PodStream(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("*/*/app"), command.PodLogDir(root), command.StreamStderr))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[default/api-7d9f/app] second run oops"})
+}
+
+func TestTail_PodSeveral(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("kube-system/dns-1/dns"), command.Pod("default/api-7d9f/proxy"),
+		command.PodLogDir(root)))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[default/api-7d9f/proxy] proxy up", "[kube-system/dns-1/dns] dns up"})
+}
+
+func TestTail_PodSameNameInNamespaces(t *testing.T) {
+	root := t.TempDir()
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	writePodLog(t, root, "default_api-1_1111", "app", 0, start, "stdout F from default")
+	writePodLog(t, root, "staging_api-1_2222", "app", 0, start, "stdout F from staging")
+
+	result := run.Quick(command.Tail(command.Pod("*/api-1/app"), command.PodLogDir(root)))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[default/api-1/app] from default", "[staging/api-1/app] from staging"})
+}
+
+func TestTail_PodNotFound(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("default/web/app"), command.PodLogDir(root)))
+
+	assertion.Equal(t, errors.Is(result.Err, command.ErrNoPodLogs), true, "errors.Is ErrNoPodLogs")
+	assertion.ErrorContains(t, result.Err, `"default/web/app"`)
+}
+
+func TestTail_PodInvalid(t *testing.T) {
+	result := run.Quick(command.Tail(command.Pod("default")))
+
+	assertion.ErrorContains(t, result.Err, `invalid pod "default"`)
+}
+
+func TestTail_PodWithFiles(t *testing.T) {
+	result := run.Quick(command.Tail(writeFile(t, "a\n"), command.Pod("default/api/app")))
+
+	assertion.ErrorContains(t, result.Err, "Pod can't be combined with files")
+}
+
+func TestTail_FollowPodRestart(t *testing.T) {
+	root := podLayout(t)
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(command.Pod("default/api-7d9f/app"), command.PodLogDir(root),
+		command.LineCount(1), command.Follow, command.ClockFlag{Clock: clock}))
+	waitForOutput(t, out, "[default/api-7d9f/app] second run 2\n")
+	clock.WaitForPolls(t, 2)
+	writePodLog(t, root, "default_api-7d9f_1111", "app", 3, time.Now(), "stdout F third run 1")
+	waitForOutput(t, out, "[default/api-7d9f/app] second run 2\n[default/api-7d9f/app] third run 1\n")
+	appendFile(t, filepath.Join(root, "default_api-7d9f_1111", "app", "3.log"), "2026-10-17T10:00:00Z stdout F third run 2\n")
+	waitForOutput(t, out, "[default/api-7d9f/app] second run 2\n[default/api-7d9f/app] third run 1\n[default/api-7d9f/app] third run 2\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_FollowPodLogRotated(t *testing.T) {
+	root := podLayout(t)
+	clock := &manualClock{now: time.Unix(0, 0)}
+	dir := filepath.Join(root, "default_api-7d9f_1111", "app")
+
+	out, stop := startFollow(t, command.Tail(command.Pod("default/api-7d9f/app"), command.PodLogDir(root),
+		command.LineCount(1), command.Follow, command.ClockFlag{Clock: clock}))
+	waitForOutput(t, out, "[default/api-7d9f/app] second run 2\n")
+	clock.WaitForPolls(t, 2)
+	// The kubelet renames the log and starts a new file of the same name
+	appendFile(t, filepath.Join(dir, "1.log"), "2026-10-17T10:00:00Z stdout F before rotation\n")
+	if err := os.Rename(filepath.Join(dir, "1.log"), filepath.Join(dir, "1.log.20261017-100100")); err != nil {
+		t.Fatal(err)
+	}
+	writePodLog(t, root, "default_api-7d9f_1111", "app", 1, time.Now(), "stdout F after rotation")
+	waitForOutput(t, out, "[default/api-7d9f/app] second run 2\n[default/api-7d9f/app] before rotation\n"+
+		"[default/api-7d9f/app] after rotation\n")
+	appendFile(t, filepath.Join(dir, "1.log"), "2026-10-17T10:00:00Z stdout F still followed\n")
+	waitForOutput(t, out, "[default/api-7d9f/app] second run 2\n[default/api-7d9f/app] before rotation\n"+
+		"[default/api-7d9f/app] after rotation\n[default/api-7d9f/app] still followed\n")
+
+	assertion.NoError(t, stop())
+}
+
+// removingWriter is a syncBuffer that removes a file the first time it is
+// written to, as the kubelet may at any time.
+type removingWriter struct {
+	syncBuffer
+	path string
+	once sync.Once
+}
+
+func (w *removingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { os.Remove(w.path) })
+	return w.syncBuffer.Write(p)
+}
+
+func TestTail_FollowPodLogRemoved(t *testing.T) {
+	root := podLayout(t)
+	clock := fixedClock{now: time.Unix(0, 0)}
+	// The proxy's log is listed, then removed before it is opened
+	out := &removingWriter{path: filepath.Join(root, "default_api-7d9f_1111", "proxy", "0.log")}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Tail(command.Pod("*/*"), command.PodLogDir(root), command.LineCount(1), command.Follow,
+			command.ClockFlag{Clock: clock}).Executor()(ctx, strings.NewReader(""), out, io.Discard)
+	}()
+	waitForOutput(t, &out.syncBuffer, "[default/api-7d9f/app] second run 2\n[kube-system/dns-1/dns] dns up\n")
+	cancel()
+
+	assertion.NoError(t, <-done)
+}
+
+func TestTail_FollowPodAppears(t *testing.T) {
+	root := t.TempDir()
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, stop := startFollow(t, command.Tail(command.Pod("default/web-*/web"), command.PodLogDir(root),
+		command.Follow, command.ClockFlag{Clock: clock}))
+	clock.WaitForPolls(t, 2)
+	writePodLog(t, root, "default_web-1_3333", "web", 0, time.Now(), "stdout F listening")
+	waitForOutput(t, out, "[default/web-1/web] listening\n")
+
+	assertion.NoError(t, stop())
+}
+
+func TestTail_PodsShareOutputBudget(t *testing.T) {
+	root := podLayout(t)
+
+	result := run.Quick(command.Tail(command.Pod("default/api-*"), command.PodLogDir(root), command.LineCount(1),
+		command.MaxOutputBytes(40)))
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"[default/api-7d9f/proxy] proxy up"})
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultRecordFlushTimeout = time.Second
+
+// grouper joins continuation lines onto the line that starts their record,
+// so a stack trace is counted and emitted as one record. Records are
+// returned as a single string with the lines separated by "\n". With a
+// ContainerFormat, the lines are first unwrapped from the runtime's entries.
+// With FormatCSV, the lines of a row with quoted newlines are joined, and
+// the first row of an input is taken as its header.
+type grouper struct {
+	engine  *engine
+	joiner  *containerJoiner
+	pending []string
+	updated time.Time
+	open    bool // whether a quoted CSV field is open
+	header  bool // whether the next CSV row is the input's header
+}
+
+// newGrouper returns a grouper for lines read from the start of an input.
+func (e *engine) newGrouper() *grouper {
+	return &grouper{engine: e, joiner: e.newJoiner(), header: true}
+}
+
+// add appends lines read at now and returns the records they complete.
+// Without a continuation pattern every line is its own record.
+func (g *grouper) add(lines []string, now time.Time) []string {
+	if len(lines) > 0 {
+		g.updated = now
+	}
+	if g.joiner != nil {
+		lines = g.joiner.add(lines)
+	}
+	return g.group(lines)
+}
+
+// group returns the records completed by lines.
+func (g *grouper) group(lines []string) []string {
+	if g.engine.Format == FormatCSV {
+		return g.groupCSV(lines)
+	}
+	if g.engine.continuation == nil {
+		return lines
+	}
+	var records []string
+	for _, line := range lines {
+		if len(g.pending) > 0 && !g.engine.continuation.MatchString(g.engine.text(line)) {
+			records = append(records, g.take())
+		}
+		g.pending = append(g.pending, line)
+	}
+	return records
+}
+
+// groupCSV returns the CSV rows completed by lines.
+func (g *grouper) groupCSV(lines []string) []string {
+	var records []string
+	for _, line := range lines {
+		g.pending = append(g.pending, line)
+		if g.open = g.engine.csvOpen(g.open, g.engine.text(line)); !g.open {
+			records = append(records, g.take())
+		}
+	}
+	return g.takeHeader(records)
+}
+
+// takeHeader takes the input's header row from the start of records.
+func (g *grouper) takeHeader(records []string) []string {
+	if !g.header || g.engine.Format != FormatCSV || len(records) == 0 {
+		return records
+	}
+	g.header = false
+	g.engine.setCSVHeader(records[0])
+	return records[1:]
+}
+
+// idle reports whether the pending record, or a line still missing entries,
+// has not grown for the flush timeout. A CSV row still inside a quote is
+// never idle, since emitting part of it would leave the rest out of step.
+func (g *grouper) idle(now time.Time) bool {
+	held := len(g.pending) > 0 && g.engine.Format != FormatCSV
+	return (held || g.joiner.pending()) && now.Sub(g.updated) >= g.engine.recordFlushTimeout()
+}
+
+// flush returns the pending records, if any, and starts a new one.
+func (g *grouper) flush() []string {
+	var records []string
+	if g.joiner != nil {
+		records = g.group(g.joiner.flush())
+	}
+	if len(g.pending) > 0 {
+		records = append(records, g.take())
+	}
+	return g.takeHeader(records)
+}
+
+// take returns the pending record and starts a new one.
+func (g *grouper) take() string {
+	record := strings.Join(g.pending, "\n")
+	g.pending, g.open = nil, false
+	return record
+}
+
+// reverseGrouper is the grouper for lines read from last to first: it holds
+// continuation lines until it reaches the line that starts their record.
+// With FormatCSV, a line starts a row when the lines after it up to the
+// next row hold an even number of quotes, as they do in valid CSV.
+type reverseGrouper struct {
+	engine  *engine
+	joiner  *reverseJoiner
+	pending []string // continuation lines, last first
+	odd     bool     // whether the pending CSV lines hold an odd number of quotes
+}
+
+func (e *engine) newReverseGrouper() *reverseGrouper {
+	return &reverseGrouper{engine: e, joiner: e.newReverseJoiner()}
+}
+
+// add takes the line preceding those already added and returns the records
+// it completes, last first.
+func (g *reverseGrouper) add(line string) []string {
+	if g.joiner == nil {
+		if record, ok := g.group(line); ok {
+			return []string{record}
+		}
+		return nil
+	}
+	var records []string
+	for _, line := range g.joiner.add(line) {
+		if record, ok := g.group(line); ok {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// group takes the line preceding those already grouped and returns the
+// record it starts, if any.
+func (g *reverseGrouper) group(line string) (string, bool) {
+	if g.engine.Format == FormatCSV {
+		g.pending = append(g.pending, line)
+		g.odd = g.odd != (strings.Count(g.engine.text(line), string(g.engine.csvQuote()))%2 == 1)
+		if g.odd {
+			return "", false
+		}
+		return g.take(), true
+	}
+	if g.engine.continuation == nil {
+		return line, true
+	}
+	g.pending = append(g.pending, line)
+	if g.engine.continuation.MatchString(g.engine.text(line)) {
+		return "", false
+	}
+	return g.take(), true
+}
+
+// flush returns the records held at the start of the input, last first, as
+// the grouper would for continuation lines at the start of the input. CSV
+// lines still inside a quote there are not valid CSV, and each is returned
+// as a row of its own rather than joined up to the start of the input.
+func (g *reverseGrouper) flush() []string {
+	var records []string
+	if g.joiner != nil {
+		for _, line := range g.joiner.flush() {
+			if record, ok := g.group(line); ok {
+				records = append(records, record)
+			}
+		}
+	}
+	if g.odd {
+		records = append(records, g.pending...)
+		g.pending, g.odd = nil, false
+	}
+	if len(g.pending) > 0 {
+		records = append(records, g.take())
+	}
+	return records
+}
+
+// take returns the held lines as one record and starts a new one.
+func (g *reverseGrouper) take() string {
+	slices.Reverse(g.pending)
+	record := strings.Join(g.pending, "\n")
+	g.pending, g.odd = nil, false
+	return record
+}
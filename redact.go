@@ -0,0 +1,224 @@
+package command
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const defaultRedactReplacement = "[REDACTED]"
+
+// redactor finds one kind of text to hide. When its pattern has a group,
+// only the first group is hidden. valid, if set, accepts a match, and
+// returns where the text to hide ends, which may be before the match does.
+type redactor struct {
+	kind  string
+	re    *regexp.Regexp
+	valid func(line string, start, end int) (int, bool) // nil accepts every match
+}
+
+// detectors are the built-in redactors of each Redact.
+var detectors = map[Redact][]redactor{
+	RedactJWT: {{
+		kind: "jwt",
+		re:   regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{4,}\.eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]*`),
+	}},
+	RedactBearer: {{
+		kind: "bearer",
+		re:   regexp.MustCompile(`\b[Bb]earer\s+([A-Za-z0-9._~+/-]{16,}=*)`),
+	}},
+	RedactAWSKey: {{
+		kind: "aws-key",
+		re:   regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA|ASCA)[A-Z0-9]{16})\b`),
+	}, {
+		kind: "aws-key",
+		re:   regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`),
+	}},
+	RedactEmail: {{
+		kind: "email",
+		re:   regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`),
+	}},
+	RedactIPv4: {{
+		kind:  "ipv4",
+		re:    regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+		valid: standaloneIPv4,
+	}},
+	RedactIPv6: {{
+		kind:  "ipv6",
+		re:    regexp.MustCompile(`[0-9A-Fa-f:]*:[0-9A-Fa-f:]*:[0-9A-Fa-f:]*(?:\.\d{1,3}){0,3}`),
+		valid: validIPv6,
+	}},
+	RedactCreditCard: {{
+		kind:  "credit-card",
+		re:    regexp.MustCompile(`\b[2-6]\d{3}(?:[ -]?\d){9,15}\b`),
+		valid: validCardNumber,
+	}},
+}
+
+// standaloneIPv4 accepts an address that is not part of a longer run of
+// dotted numbers, such as the version "1.2.3.4.5", but may end a sentence.
+func standaloneIPv4(line string, start, end int) (int, bool) {
+	digit := func(i int) bool { return i >= 0 && i < len(line) && line[i] >= '0' && line[i] <= '9' }
+	before := start > 0 && (digit(start-1) || line[start-1] == '.' && digit(start-2))
+	after := end < len(line) && (digit(end) || line[end] == '.' && digit(end+1))
+	return end, !before && !after
+}
+
+// validIPv6 accepts a standalone IPv6 address holding at least one digit,
+// which leaves out times such as "10:00:01", MAC addresses and "::". Colons
+// the match took from the text after the address, as in
+// "from 2001:db8::5: denied", are left out of it.
+func validIPv6(line string, start, end int) (int, bool) {
+	word := "0123456789ABCDEFabcdefghijklmnopqrstuvwxyzGHIJKLMNOPQRSTUVWXYZ_"
+	if start > 0 && strings.ContainsRune(word, rune(line[start-1])) ||
+		end < len(line) && strings.ContainsRune(word, rune(line[end])) {
+		return 0, false
+	}
+	for ; end > start; end-- {
+		addr, err := netip.ParseAddr(line[start:end])
+		if err == nil && addr.Is6() && strings.ContainsAny(line[start:end], "0123456789abcdefABCDEF") {
+			return end, true
+		}
+		if line[end-1] != ':' {
+			break
+		}
+	}
+	return 0, false
+}
+
+// validCardNumber accepts a card number of 13 to 19 digits, optionally
+// grouped with spaces or dashes, that passes the Luhn check.
+func validCardNumber(line string, start, end int) (int, bool) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(line[start:end])
+	if len(digits) < 13 || len(digits) > 19 {
+		return 0, false
+	}
+	sum := 0
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return end, sum%10 == 0
+}
+
+// compileRedactors compiles the redactors of each Redact and RedactPattern,
+// and sets up the MaskHash key.
+func (e *engine) compileRedactors() error {
+	for _, r := range e.Redacts {
+		found, ok := detectors[r]
+		if !ok {
+			return fmt.Errorf("unknown redactor %q", string(r))
+		}
+		e.redactors = append(e.redactors, found...)
+	}
+	patterns, err := compilePatterns(e.Redactions)
+	if err != nil {
+		return err
+	}
+	for _, re := range patterns {
+		e.redactors = append(e.redactors, redactor{kind: "pattern", re: re})
+	}
+	switch e.RedactMask {
+	case MaskReplacement:
+	case MaskHash:
+		e.hashKey = []byte(e.RedactHashKey)
+		if len(e.hashKey) == 0 {
+			e.hashKey = make([]byte, 32)
+			rand.Read(e.hashKey)
+		}
+	default:
+		return fmt.Errorf("unknown redact mask %q", string(e.RedactMask))
+	}
+	return nil
+}
+
+// redaction is a stretch of a record to hide.
+type redaction struct {
+	start, end int
+	kind       string
+}
+
+// redact hides what the redactors find in a record. Where their finds
+// overlap, the one that starts first wins, then the longer.
+func (e *engine) redact(record string) string {
+	if len(e.redactors) == 0 {
+		return record
+	}
+	var found []redaction
+	for _, r := range e.redactors {
+		for _, m := range r.re.FindAllStringSubmatchIndex(record, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 {
+				start, end = m[2], m[3]
+			}
+			if start >= end {
+				continue
+			}
+			if r.valid != nil {
+				var ok bool
+				if end, ok = r.valid(record, start, end); !ok {
+					continue
+				}
+			}
+			found = append(found, redaction{start, end, r.kind})
+		}
+	}
+	if len(found) == 0 {
+		return record
+	}
+	slices.SortStableFunc(found, func(a, b redaction) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return b.end - a.end
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, r := range found {
+		if r.start < last {
+			continue
+		}
+		b.WriteString(record[last:r.start])
+		b.WriteString(e.mask(r.kind, record[r.start:r.end]))
+		last = r.end
+	}
+	b.WriteString(record[last:])
+	return b.String()
+}
+
+// redactAll redacts each record.
+func (e *engine) redactAll(records []string) []string {
+	if len(e.redactors) == 0 {
+		return records
+	}
+	redacted := make([]string, len(records))
+	for i, record := range records {
+		redacted[i] = e.redact(record)
+	}
+	return redacted
+}
+
+// mask returns what hidden text is replaced with.
+func (e *engine) mask(kind, text string) string {
+	if e.RedactMask == MaskHash {
+		h := hmac.New(sha256.New, e.hashKey)
+		h.Write([]byte(text))
+		return "[" + kind + ":" + hex.EncodeToString(h.Sum(nil))[:8] + "]"
+	}
+	if e.RedactReplacement == "" {
+		return defaultRedactReplacement
+	}
+	return string(e.RedactReplacement)
+}
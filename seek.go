@@ -0,0 +1,72 @@
+package command
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// SeekTime returns the byte offset of the first line of a log whose
+// timestamps increase that is timestamped at or after t, or size if there is
+// none. Timestamps are extracted with format, and lines without one are
+// skipped. The log is binary searched, so only a few blocks are read however
+// large it is.
+func SeekTime(r io.ReaderAt, size int64, t time.Time, format TimeFormat) (int64, error) {
+	e, err := newEngine(command{Flags: flags{TimeFormat: format}})
+	if err != nil {
+		return 0, err
+	}
+	return e.seekTime(r, size, t)
+}
+
+// SeekKey returns the byte offset of the first line of a sorted file that is
+// not less than key, or size if there is none. With a prefix as the key, that
+// is the first line starting with the prefix, if there is one.
+func SeekKey(r io.ReaderAt, size int64, key string) (int64, error) {
+	return searchLines(r, size, func(line string) (bool, bool) {
+		return strings.Compare(line, key) < 0, true
+	})
+}
+
+// searchLines binary searches a seekable input for the offset of the first
+// line that is not before the target, or size if there is none. before
+// reports whether a line sorts before the target, and whether the line can be
+// compared at all; lines that can't are skipped.
+func searchLines(r io.ReaderAt, size int64, before func(line string) (bool, bool)) (int64, error) {
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, ok, isBefore, err := probeLine(r, mid, size, before)
+		if err != nil {
+			return 0, err
+		}
+		if !ok || !isBefore {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	start, ok, _, err := probeLine(r, lo, size, before)
+	if err != nil || !ok {
+		return size, err
+	}
+	return start, nil
+}
+
+// probeLine finds the first comparable line starting at or after off and
+// returns its offset and whether it sorts before the target.
+func probeLine(r io.ReaderAt, off, size int64, before func(string) (bool, bool)) (int64, bool, bool, error) {
+	f := newForwardReader(r, off, size, lineDecoder{})
+	if err := f.skipPartial(r); err != nil {
+		return 0, false, false, err
+	}
+	for {
+		line, start, ok, err := f.next()
+		if err != nil || !ok {
+			return 0, false, false, err
+		}
+		if isBefore, comparable := before(line); comparable {
+			return start, true, isBefore, nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package command_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// ==============================================================================
+// Test Seeking by Key
+// ==============================================================================
+
+func TestSeekKey(t *testing.T) {
+	content := "apple\nbanana\ncherry 1\ncherry 2\ndate\n"
+	r := strings.NewReader(content)
+
+	tests := []struct {
+		key      string
+		expected int64
+	}{
+		{key: "apple", expected: 0},
+		{key: "b", expected: 6},
+		{key: "cherry", expected: 13},
+		{key: "cherry 2", expected: 22},
+		{key: "coconut", expected: 31},
+		{key: "aardvark", expected: 0},
+		{key: "zebra", expected: int64(len(content))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			offset, err := command.SeekKey(r, r.Size(), tt.key)
+
+			assertion.NoError(t, err)
+			assertion.Equal(t, offset, tt.expected, "offset")
+		})
+	}
+}
+
+func TestSeekKey_Empty(t *testing.T) {
+	offset, err := command.SeekKey(strings.NewReader(""), 0, "a")
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, offset, int64(0), "offset")
+}
+
+func TestSeekKey_NoTrailingNewline(t *testing.T) {
+	r := strings.NewReader("a\nb\nc")
+	offset, err := command.SeekKey(r, r.Size(), "c")
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, offset, int64(4), "offset")
+}
+
+func TestSeekKey_LargeFile(t *testing.T) {
+	var b strings.Builder
+	offsets := make(map[string]int64)
+	for i := 0; i < 50000; i++ {
+		key := fmt.Sprintf("key-%06d", i*2)
+		offsets[key] = int64(b.Len())
+		fmt.Fprintf(&b, "%s value\n", key)
+	}
+	path := writeFile(t, b.String())
+	f, err := os.Open(path)
+	assertion.NoError(t, err)
+	defer f.Close()
+
+	for _, key := range []string{"key-000000", "key-012346", "key-099998"} {
+		offset, err := command.SeekKey(f, int64(b.Len()), key)
+
+		assertion.NoError(t, err)
+		assertion.Equal(t, offset, offsets[key], key)
+	}
+
+	// An odd key falls between two lines
+	offset, err := command.SeekKey(f, int64(b.Len()), "key-012347")
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, offset, offsets["key-012348"], "insertion point")
+}
+
+// ==============================================================================
+// Test Seeking by Time
+// ==============================================================================
+
+func TestSeekTime(t *testing.T) {
+	content := minuteLog(20000)
+	r := strings.NewReader(content)
+
+	offset, err := command.SeekTime(r, r.Size(), sinceBase.Add(12345*time.Minute), command.TimeRFC3339)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, strings.HasPrefix(content[offset:], "2026-10-26T01:45:00Z entry 12345\n"), true, "line at offset")
+}
+
+func TestSeekTime_SkipsUntimestampedLines(t *testing.T) {
+	content := "2026-10-17T12:00:00Z a\n  detail\n2026-10-17T12:01:00Z b\n"
+	r := strings.NewReader(content)
+
+	offset, err := command.SeekTime(r, r.Size(), sinceBase.Add(time.Second), command.TimeRFC3339)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, offset, int64(strings.Index(content, "2026-10-17T12:01")), "offset")
+}
+
+func TestSeekTime_AfterEnd(t *testing.T) {
+	r := strings.NewReader(minuteLog(10))
+
+	offset, err := command.SeekTime(r, r.Size(), sinceBase.Add(time.Hour), command.TimeRFC3339)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, offset, r.Size(), "offset")
+}
+
+func TestSeekTime_InvalidFormat(t *testing.T) {
+	r := strings.NewReader("a\n")
+
+	_, err := command.SeekTime(r, r.Size(), sinceBase, command.TimeFormat{Pattern: "("})
+
+	assertion.ErrorContains(t, err, "missing closing )")
+}
@@ -0,0 +1,137 @@
+package command
+
+import (
+	"io"
+	"math"
+	"time"
+)
+
+// sinceActive reports whether a Since or SinceTime window was given.
+func (e *engine) sinceActive() bool {
+	return e.Since > 0 || !time.Time(e.SinceTime).IsZero()
+}
+
+// cutoff returns the start of the time window.
+func (e *engine) cutoff() time.Time {
+	if t := time.Time(e.SinceTime); !t.IsZero() {
+		return t
+	}
+	return e.clock.Now().Add(-time.Duration(e.Since))
+}
+
+// recordTime extracts a record's timestamp with the configured TimeFormat.
+// Layouts without a year, such as syslog's, take the most recent year that
+// does not put the time in the future.
+func (e *engine) recordTime(record string) (time.Time, bool) {
+	m := e.timePattern.FindStringSubmatch(e.text(record))
+	if m == nil {
+		return time.Time{}, false
+	}
+	value := m[0]
+	if len(m) > 1 {
+		value = m[1]
+	}
+	t, err := time.ParseInLocation(e.timeFormat().Layout, value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if t.Year() == 0 {
+		now := e.clock.Now()
+		t = t.AddDate(now.Year(), 0, 0)
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+	}
+	return t, true
+}
+
+func (e *engine) timeFormat() TimeFormat {
+	if e.TimeFormat.Pattern == "" {
+		return TimeRFC3339
+	}
+	return e.TimeFormat
+}
+
+// dropBefore drops the records preceding the first one timestamped at or
+// after the cutoff. Timestamps are assumed to increase through the input.
+func (e *engine) dropBefore(records []string) []string {
+	cutoff := e.cutoff()
+	for i, record := range records {
+		if t, ok := e.recordTime(record); ok && !t.Before(cutoff) {
+			return records[i:]
+		}
+	}
+	return nil
+}
+
+// entryTime returns the timestamp of a line as read from its input. The
+// entries of a container runtime's log are timestamped by the runtime, and
+// only an entry without a time of its own is timed by what the container
+// wrote.
+func (e *engine) entryTime(line string) (time.Time, bool) {
+	entry, ok := e.parseContainer(line)
+	if !ok {
+		return e.recordTime(line)
+	}
+	if t, ok := entry.time(); ok {
+		return t, true
+	}
+	return e.recordTime(entry.message)
+}
+
+// entriesSince drops the container entries before the time window, which
+// starts at the first entry timestamped at or after the cutoff. It is
+// dropBefore for lines not yet unwrapped, whose records may have lost their
+// entries' timestamps. It reports whether the window has started.
+func (e *engine) entriesSince(lines []string) ([]string, bool) {
+	cutoff := e.cutoff()
+	for i, line := range lines {
+		if t, ok := e.entryTime(line); ok && !t.Before(cutoff) {
+			return lines[i:], true
+		}
+	}
+	return nil, false
+}
+
+// seekTime returns the offset of the first line timestamped at or after
+// cutoff in a seekable input whose timestamps increase, or size if there is
+// none. Lines without a timestamp belong to the line before them and never
+// start the window. Container entries are timed as entryTime does.
+func (e *engine) seekTime(r io.ReaderAt, size int64, cutoff time.Time) (int64, error) {
+	return searchLines(r, size, func(line string) (bool, bool) {
+		t, ok := e.entryTime(line)
+		return ok && t.Before(cutoff), ok
+	})
+}
+
+// readSince reads the records of a seekable input from the start of the time
+// window up to end and returns those that pass the filter. It reports
+// whether the last of them is the input's final line and that line had no
+// newline.
+func (e *engine) readSince(r io.ReaderAt, end int64, decoder lineDecoder) ([]string, bool, error) {
+	start, err := e.seekTime(r, end, e.cutoff())
+	if err != nil {
+		return nil, false, err
+	}
+	f := newForwardReader(r, start, end, decoder)
+	var lines []string
+	for {
+		line, _, ok, err := f.next()
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			break
+		}
+		lines = append(lines, line)
+	}
+	g := e.newGrouper()
+	g.header = start == 0
+	records := g.add(lines, time.Time{})
+	records = append(records, g.flush()...)
+	partial := !f.terminated && len(records) > 0 && e.keep(records[len(records)-1])
+	return e.filter(records), partial, nil
+}
+
+// unlimited is the line count used when a time window alone selects records.
+const unlimited = math.MaxInt
@@ -0,0 +1,191 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+var sinceBase = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+// minuteLog returns n lines timestamped a minute apart from sinceBase. Every
+// third entry is followed by an untimestamped continuation line.
+func minuteLog(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%s entry %d\n", sinceBase.Add(time.Duration(i)*time.Minute).Format(time.RFC3339), i)
+		if i%3 == 0 {
+			fmt.Fprintf(&b, "  detail %d\n", i)
+		}
+	}
+	return b.String()
+}
+
+// minuteLines returns the lines of minuteLog(n).
+func minuteLines(n int) []string {
+	return strings.Split(strings.TrimSuffix(minuteLog(n), "\n"), "\n")
+}
+
+// ==============================================================================
+// Test Time Windows on Stdin
+// ==============================================================================
+
+func TestTail_SinceTime(t *testing.T) {
+	result := run.Command(command.Tail(command.SinceTime(sinceBase.Add(2*time.Minute)))).
+		WithStdinLines(
+			"2026-10-17T12:00:00Z a",
+			"2026-10-17T12:01:00Z b",
+			"2026-10-17T12:02:00Z c",
+			"  continuation",
+			"2026-10-17T12:03:00Z d",
+		).Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"2026-10-17T12:02:00Z c",
+		"  continuation",
+		"2026-10-17T12:03:00Z d",
+	})
+}
+
+func TestTail_SinceDuration(t *testing.T) {
+	clock := fixedClock{now: sinceBase.Add(30 * time.Minute)}
+	result := run.Command(command.Tail(command.Since(15*time.Minute), command.ClockFlag{Clock: clock})).
+		WithStdinLines(minuteLines(30)...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Equal(t, result.Stdout[0], "2026-10-17T12:15:00Z entry 15", "first line")
+	assertion.Equal(t, result.Stdout[len(result.Stdout)-1], "2026-10-17T12:29:00Z entry 29", "last line")
+	assertion.Count(t, result.Stdout, 20)
+}
+
+func TestTail_SinceWithLineCount(t *testing.T) {
+	result := run.Command(command.Tail(command.SinceTime(sinceBase), command.LineCount(2))).
+		WithStdinLines(
+			"2026-10-17T11:00:00Z old",
+			"2026-10-17T12:00:00Z a",
+			"2026-10-17T12:01:00Z b",
+			"2026-10-17T12:02:00Z c",
+		).Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"2026-10-17T12:01:00Z b", "2026-10-17T12:02:00Z c"})
+}
+
+func TestTail_SinceNothingNewer(t *testing.T) {
+	result := run.Command(command.Tail(command.SinceTime(sinceBase.Add(time.Hour)))).
+		WithStdinLines(minuteLines(10)...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Empty(t, result.Stdout)
+}
+
+func TestTail_SinceSyslog(t *testing.T) {
+	clock := fixedClock{now: time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)}
+	result := run.Command(command.Tail(command.TimeSyslog, command.ClockFlag{Clock: clock},
+		command.SinceTime(time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local)))).
+		WithStdinLines(
+			"Dec 31 23:59:00 host app: last year",
+			"Jan  1 22:00:00 host app: too early",
+			"Jan  1 23:30:00 host app: in window",
+		).Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"Jan  1 23:30:00 host app: in window"})
+}
+
+func TestTail_SinceCommonLog(t *testing.T) {
+	result := run.Command(command.Tail(command.TimeCommonLog,
+		command.SinceTime(time.Date(2026, 10, 10, 13, 55, 36, 0, time.UTC)))).
+		WithStdinLines(
+			`127.0.0.1 - - [10/Oct/2026:13:55:35 +0000] "GET /a HTTP/1.1" 200 2326`,
+			`127.0.0.1 - - [10/Oct/2026:15:55:36 +0200] "GET /b HTTP/1.1" 200 2326`,
+			`127.0.0.1 - - [10/Oct/2026:13:55:37 +0000] "GET /c HTTP/1.1" 200 2326`,
+		).Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Count(t, result.Stdout, 2)
+	assertion.Equal(t, strings.Contains(result.Stdout[0], "GET /b"), true, "first selected request")
+}
+
+func TestTail_SinceCustomTimeFormat(t *testing.T) {
+	format := command.TimeFormat{Pattern: `ts=(\d+/\d+/\d+ \d+:\d+)`, Layout: "2006/01/02 15:04"}
+	result := run.Command(command.Tail(format, command.SinceTime(time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)))).
+		WithStdinLines(
+			"level=info ts=2026/03/01 08:59 msg=a",
+			"level=info ts=2026/03/01 09:00 msg=b",
+		).Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"level=info ts=2026/03/01 09:00 msg=b"})
+}
+
+func TestTail_SinceInvalidTimePattern(t *testing.T) {
+	result := run.Command(command.Tail(command.TimeFormat{Pattern: "(", Layout: time.RFC3339}, command.Since(time.Minute))).
+		WithStdinLines("a").
+		Run()
+
+	assertion.ErrorContains(t, result.Err, "missing closing )")
+}
+
+// ==============================================================================
+// Test Time Windows on Files
+// ==============================================================================
+
+func TestTail_SinceFileBinarySearch(t *testing.T) {
+	content := minuteLog(20000)
+	path := writeFile(t, content)
+
+	for _, offset := range []time.Duration{-time.Hour, 0, time.Minute, 7 * time.Minute, 9999 * time.Minute, 19999 * time.Minute, 20000 * time.Minute} {
+		t.Run(offset.String(), func(t *testing.T) {
+			since := command.SinceTime(sinceBase.Add(offset))
+
+			var fromStdin bytes.Buffer
+			err := command.Tail(since).Executor()(context.Background(), strings.NewReader(content), &fromStdin, io.Discard)
+
+			assertion.NoError(t, err)
+			assertion.Equal(t, runFile(t, command.Tail(path, since)), fromStdin.String(), "output")
+		})
+	}
+}
+
+func TestTail_SinceFileWithFilter(t *testing.T) {
+	path := writeFile(t, minuteLog(100))
+
+	assertion.Equal(t, runFile(t, command.Tail(path, command.SinceTime(sinceBase.Add(90*time.Minute)),
+		command.IncludePattern("entry 9[0-2]"), command.IndentedContinuation)),
+		"2026-10-17T13:30:00Z entry 90\n  detail 90\n2026-10-17T13:31:00Z entry 91\n2026-10-17T13:32:00Z entry 92\n",
+		"output")
+}
+
+// ==============================================================================
+// Test Time Windows in Follow Mode
+// ==============================================================================
+
+func TestTail_FollowSinceThenStreams(t *testing.T) {
+	path := writeFile(t, minuteLog(10))
+	clock := fixedClock{now: sinceBase.Add(10 * time.Minute)}
+
+	out, stop := startFollow(t, command.Tail(path, command.Follow, command.Since(2*time.Minute), command.ClockFlag{Clock: clock}))
+	waitForLines(t, out, 3)
+	appendFile(t, path, "no timestamp\n")
+	lines := waitForLines(t, out, 4)
+
+	assertion.NoError(t, stop())
+	assertion.Lines(t, lines, []string{
+		"2026-10-17T12:08:00Z entry 8",
+		"2026-10-17T12:09:00Z entry 9",
+		"  detail 9",
+		"no timestamp",
+	})
+}
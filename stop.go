@@ -0,0 +1,59 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrFollowStopped is wrapped by the errors that end follow mode when
+	// one of its limits is reached, so that callers can tell a limit from a
+	// failure.
+	ErrFollowStopped = errors.New("follow stopped")
+
+	// ErrMaxDuration ends follow mode after FollowDuration.
+	ErrMaxDuration = fmt.Errorf("%w: duration reached", ErrFollowStopped)
+
+	// ErrIdleTimeout ends follow mode after FollowIdleTimeout without input.
+	ErrIdleTimeout = fmt.Errorf("%w: idle timeout", ErrFollowStopped)
+
+	// ErrMaxLines ends follow mode after FollowMaxLines new records.
+	ErrMaxLines = fmt.Errorf("%w: line limit reached", ErrFollowStopped)
+)
+
+// expired returns the error that ends follow mode at now, if a time limit
+// has passed: following began at began, and input last arrived at updated.
+func (e *engine) expired(began, updated, now time.Time) error {
+	if err := e.waitExpired(began, now); err != nil {
+		return err
+	}
+	if e.FollowDuration > 0 && now.Sub(began) >= time.Duration(e.FollowDuration) {
+		return fmt.Errorf("%w after %s", ErrMaxDuration, time.Duration(e.FollowDuration))
+	}
+	if e.FollowIdle > 0 && now.Sub(updated) >= time.Duration(e.FollowIdle) {
+		return fmt.Errorf("%w after %s", ErrIdleTimeout, time.Duration(e.FollowIdle))
+	}
+	return nil
+}
+
+// untilLimit returns the records that fit in FollowMaxLines, counting them
+// as written, and reports whether the limit is reached.
+func (e *engine) untilLimit(records []string) ([]string, bool) {
+	if e.FollowMaxLines <= 0 {
+		return records, false
+	}
+	room := int(e.FollowMaxLines) - e.emitted
+	records = records[:min(len(records), room)]
+	e.emitted += len(records)
+	return records, e.emitted >= int(e.FollowMaxLines)
+}
+
+// limitReached returns ErrMaxLines for a record limit reached without a
+// WaitFor match, which ends following for its own reason.
+func (e *engine) limitReached(limited bool) error {
+	if !limited || e.done {
+		return nil
+	}
+	return fmt.Errorf("%w after %d lines", ErrMaxLines, e.FollowMaxLines)
+}
@@ -0,0 +1,139 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// ==============================================================================
+// Test Follow Stop Conditions
+// ==============================================================================
+
+func TestTail_FollowDuration(t *testing.T) {
+	path := writeFile(t, "a\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.Follow,
+		command.FollowDuration(10*time.Second), command.ClockFlag{Clock: clock}), nil)
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "b\n")
+	waitForOutput(t, out, "a\nb\n")
+	clock.Advance(9 * time.Second)
+	clock.WaitForPolls(t, 2)
+	select {
+	case err := <-done:
+		t.Fatalf("follow stopped before its duration: %v", err)
+	default:
+	}
+	clock.Advance(time.Second)
+	err := waitForExit(t, done)
+
+	assertion.Equal(t, errors.Is(err, command.ErrMaxDuration), true, "errors.Is ErrMaxDuration")
+	assertion.Equal(t, errors.Is(err, command.ErrFollowStopped), true, "errors.Is ErrFollowStopped")
+	assertion.ErrorContains(t, err, "after 10s")
+	assertion.Equal(t, out.String(), "a\nb\n", "output")
+}
+
+func TestTail_FollowIdleTimeout(t *testing.T) {
+	path := writeFile(t, "a\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.Follow,
+		command.FollowIdleTimeout(5*time.Second), command.ClockFlag{Clock: clock}), nil)
+	clock.WaitForPolls(t, 2)
+	clock.Advance(4 * time.Second)
+	appendFile(t, path, "b\n")
+	waitForOutput(t, out, "a\nb\n")
+	clock.Advance(4 * time.Second)
+	clock.WaitForPolls(t, 2)
+	select {
+	case err := <-done:
+		t.Fatalf("new input did not reset the idle timeout: %v", err)
+	default:
+	}
+	clock.Advance(time.Second)
+	err := waitForExit(t, done)
+
+	assertion.Equal(t, errors.Is(err, command.ErrIdleTimeout), true, "errors.Is ErrIdleTimeout")
+	assertion.Equal(t, errors.Is(err, command.ErrFollowStopped), true, "errors.Is ErrFollowStopped")
+	assertion.Equal(t, errors.Is(err, command.ErrMaxDuration), false, "errors.Is ErrMaxDuration")
+}
+
+func TestTail_FollowMaxLines(t *testing.T) {
+	path := writeFile(t, "a\nb\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.Follow, command.FollowMaxLines(2),
+		command.ClockFlag{Clock: clock}), nil)
+	waitForOutput(t, out, "a\nb\n")
+	appendFile(t, path, "c\nd\ne\n")
+	err := waitForExit(t, done)
+
+	assertion.Equal(t, errors.Is(err, command.ErrMaxLines), true, "errors.Is ErrMaxLines")
+	assertion.ErrorContains(t, err, "after 2 lines")
+	assertion.Equal(t, out.String(), "a\nb\nc\nd\n", "output")
+}
+
+func TestTail_FollowMaxLinesCountsFilteredRecords(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.Follow, command.FollowMaxLines(1),
+		command.IncludePattern("ERROR"), command.ClockFlag{Clock: clock}), nil)
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "INFO a\nERROR b\nERROR c\n")
+	err := waitForExit(t, done)
+
+	assertion.Equal(t, errors.Is(err, command.ErrMaxLines), true, "errors.Is ErrMaxLines")
+	assertion.Equal(t, out.String(), "ERROR b\n", "output")
+}
+
+func TestTail_FollowMatchBeforeLimit(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.WaitFor("ready"),
+		command.FollowMaxLines(2), command.ClockFlag{Clock: clock}), nil)
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "a\nready\nb\n")
+
+	assertion.NoError(t, waitForExit(t, done))
+	assertion.Equal(t, out.String(), "a\nready\n", "output")
+}
+
+func TestTail_FollowPipeMaxLines(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(command.LineCount(1), command.Follow,
+		command.FollowMaxLines(1), command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "a\nb\n")
+	waitForOutput(t, out, "b\n")
+	io.WriteString(w, "c\nd\n")
+	err := waitForExit(t, done)
+
+	assertion.Equal(t, errors.Is(err, command.ErrMaxLines), true, "errors.Is ErrMaxLines")
+	assertion.Equal(t, out.String(), "b\nc\n", "output")
+}
+
+func TestTail_FollowPipeIdleBeforePause(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(command.LineCount(2), command.Follow,
+		command.FollowIdleTimeout(time.Second), command.ClockFlag{Clock: clock}), r)
+	clock.WaitForPolls(t, 2)
+	clock.Advance(time.Second)
+	err := waitForExit(t, done)
+
+	assertion.Equal(t, errors.Is(err, command.ErrIdleTimeout), true, "errors.Is ErrIdleTimeout")
+	assertion.Equal(t, out.String(), "", "output")
+}
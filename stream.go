@@ -0,0 +1,332 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ring holds the last n records pushed onto it.
+type ring struct {
+	n       int
+	records []string
+	next    int // where the next record goes once the ring is full
+}
+
+func (r *ring) push(record string) {
+	if len(r.records) < r.n {
+		r.records = append(r.records, record)
+		return
+	}
+	r.records[r.next] = record
+	r.next = (r.next + 1) % r.n
+}
+
+// take returns the records held, oldest first, and empties the ring.
+func (r *ring) take() []string {
+	records := append(r.records[r.next:len(r.records):len(r.records)], r.records[:r.next]...)
+	r.records, r.next = nil, 0
+	return records
+}
+
+// streamSelector selects the last records of a stream as they are read,
+// holding only the N latest that pass the filter and fall in the time
+// window. It is selectLast for input that is never held whole.
+type streamSelector struct {
+	engine   *engine
+	last     ring
+	inWindow bool // whether a record has started the time window
+	kept     bool // whether the latest record pushed was kept
+}
+
+func (e *engine) newStreamSelector() *streamSelector {
+	return &streamSelector{engine: e, last: ring{n: e.lineCount()}, inWindow: !e.sinceActive()}
+}
+
+// admit returns the lines read that fall in the time window. The window of
+// a container runtime's log starts at an entry, by its timestamp, before
+// the entries are unwrapped. Other windows start at a record.
+func (s *streamSelector) admit(lines []string) []string {
+	if s.inWindow || s.engine.Container == ContainerNone {
+		return lines
+	}
+	lines, s.inWindow = s.engine.entriesSince(lines)
+	return lines
+}
+
+func (s *streamSelector) push(records []string) {
+	for _, record := range records {
+		s.kept = s.selects(record)
+		if s.kept {
+			s.last.push(record)
+		}
+	}
+}
+
+// selects reports whether a record passes the filter and falls in the time
+// window, which starts at the first record timestamped at or after the
+// cutoff, as in dropBefore.
+func (s *streamSelector) selects(record string) bool {
+	if !s.inWindow {
+		t, ok := s.engine.recordTime(record)
+		if !ok || t.Before(s.engine.cutoff()) {
+			return false
+		}
+		s.inWindow = true
+	}
+	return s.engine.keep(record)
+}
+
+// take returns the records selected so far. It reports whether the last of
+// them is the stream's final line and that line had no newline.
+func (s *streamSelector) take(terminated bool) ([]string, bool) {
+	partial := !terminated && s.kept
+	s.kept = false
+	return s.last.take(), partial
+}
+
+// tailStream writes the last records of a stream, such as stdin or a pipe,
+// once it reaches EOF. Only the last N records are held while it is read.
+// The stream is inspected for binary content by its first block, and named
+// in notices by name.
+func (e *engine) tailStream(stdout io.Writer, name string, r io.Reader) error {
+	reader := bufio.NewReaderSize(e.decodeStream(r), blockSize)
+	sample, err := reader.Peek(blockSize)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	decoder, show, err := e.inspect(stdout, name, sample)
+	if !show || err != nil {
+		return err
+	}
+	s := e.newStreamSelector()
+	g := e.newGrouper()
+	line := &lineBuffer{decoder: decoder}
+	for {
+		complete, _, err := line.readFrom(reader)
+		if complete {
+			s.push(g.add(s.admit([]string{line.take()}), time.Time{}))
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		break
+	}
+	terminated := !line.pending()
+	if !terminated {
+		s.push(g.add(s.admit([]string{line.take()}), time.Time{}))
+	}
+	s.push(g.flush())
+	records, partial := s.take(terminated)
+	return e.write(stdout, records, partial)
+}
+
+// streamFollower is a stream followed by followStream.
+type streamFollower struct {
+	*lineFollower
+	pipe *pipeReader
+	held *streamSelector // records held until the stream first pauses
+	live bool            // whether records are written as they arrive
+}
+
+// followStream is tailStream with Follow set. It holds the last records
+// until a poll finds nothing new to read, writes them, and from then on
+// writes records as they arrive, as follow does for files, until the stream
+// ends, ctx is cancelled, a record matches WaitFor or a limit is reached. A
+// stream that ends before it pauses is written as tailStream would.
+func (e *engine) followStream(ctx context.Context, stdout io.Writer, r io.Reader) error {
+	p := newPipeReader(e.decodeStream(r))
+	f := &streamFollower{
+		lineFollower: &lineFollower{reader: bufio.NewReaderSize(p, blockSize), records: e.newGrouper()},
+		pipe:         p,
+		held:         e.newStreamSelector(),
+	}
+	began := e.clock.Now()
+	f.updated = began
+	inspected := false
+	for {
+		select {
+		case <-ctx.Done():
+			return e.stopped(ctx)
+		case <-e.clock.After(e.sleepInterval()):
+		}
+		now := e.clock.Now()
+		unread, ended := p.state()
+		if !inspected && (len(unread) > 0 || ended) {
+			decoder, show, err := e.inspect(stdout, stdinName, unread)
+			if !show || err != nil {
+				return err
+			}
+			f.line.decoder, inspected = decoder, true
+		}
+		if inspected {
+			if over, err := e.pollStream(stdout, now, f, len(unread) == 0, ended); over || err != nil {
+				return err
+			}
+		}
+		if err := e.expired(began, f.updated, now); err != nil {
+			if !f.live {
+				// Write what the stream held before it paused
+				records, _ := f.held.take(true)
+				if err := e.write(stdout, records, false); err != nil {
+					return err
+				}
+			}
+			return err
+		}
+	}
+}
+
+// pollStream reads what has arrived on f and reports whether following is
+// over. paused tells that nothing had arrived since the last poll, and ended
+// that the stream has ended.
+func (e *engine) pollStream(stdout io.Writer, now time.Time, f *streamFollower, paused, ended bool) (bool, error) {
+	lines, err := f.drain(now)
+	if err != nil {
+		return true, err
+	}
+	if f.live {
+		if err := e.advance(stdout, now, f.lineFollower, lines); err != nil || e.done {
+			return true, err
+		}
+		if !ended {
+			return false, nil
+		}
+		if err := e.finishStream(stdout, now, f.lineFollower); err != nil {
+			return true, err
+		}
+		return true, e.streamEnded(f.pipe)
+	}
+
+	records := f.records.add(f.held.admit(lines), now)
+	terminated := !f.line.pending()
+	if ended {
+		if !terminated {
+			records = append(records, f.records.add(f.held.admit([]string{f.line.take()}), now)...)
+		}
+		records = append(records, f.records.flush()...)
+	}
+	cut := false
+	if e.WaitScanExisting {
+		whole := e.filter(records)
+		records, _ = e.untilMatch(whole)
+		cut = len(records) < len(whole)
+	}
+	f.held.push(records)
+	if ended || e.done {
+		records, partial := f.held.take(!ended || terminated || cut)
+		if err := e.write(stdout, records, partial); err != nil || e.done {
+			return true, err
+		}
+		return true, e.streamEnded(f.pipe)
+	}
+	if paused {
+		// Write what the stream held and go live
+		records, _ := f.held.take(true)
+		if err := e.write(stdout, records, false); err != nil {
+			return true, err
+		}
+		e.following, f.live = true, true
+		if err := e.advance(stdout, now, f.lineFollower, nil); err != nil {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// finishStream writes what a followed stream still holds once it has ended,
+// its final line without a newline if it had none.
+func (e *engine) finishStream(stdout io.Writer, now time.Time, f *lineFollower) error {
+	records := f.records.flush()
+	if e.partialShown() {
+		records, matched := e.untilMatch(e.filter(records))
+		if err := e.emit(stdout, now, records, false); err != nil || matched {
+			return err
+		}
+		if f.line.pending() && (f.flushed == nil || f.flushed.kept) {
+			e.untilMatch(e.filter([]string{f.line.peek()}))
+		}
+		return e.writePartial(stdout, now, f)
+	}
+	partial := f.line.pending()
+	if partial {
+		records = append(f.records.add([]string{f.line.take()}, now), f.records.flush()...)
+		partial = e.keep(records[len(records)-1])
+	}
+	whole := e.filter(records)
+	records, _ = e.untilMatch(whole)
+	return e.emit(stdout, now, records, partial && len(records) == len(whole))
+}
+
+// streamEnded returns the error for a followed stream that ended: the one
+// that ended it, or ErrNoMatch if a match was awaited.
+func (e *engine) streamEnded(p *pipeReader) error {
+	if err := p.failure(); err != nil {
+		return err
+	}
+	if e.wait != nil && !e.done {
+		return ErrNoMatch
+	}
+	return nil
+}
+
+// pipeReader reads a stream in the background so that it can be polled like
+// a growing file: Read returns io.EOF when nothing new has arrived. Once the
+// stream ends, state reports it. A read blocked on a stream that never ends
+// outlives the command.
+type pipeReader struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	err error // why the stream ended, if it has
+}
+
+func newPipeReader(r io.Reader) *pipeReader {
+	p := &pipeReader{}
+	go func() {
+		buf := make([]byte, blockSize)
+		for {
+			n, err := r.Read(buf)
+			p.mu.Lock()
+			p.buf.Write(buf[:n])
+			p.err = err
+			p.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *pipeReader) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return p.buf.Read(b)
+}
+
+// state returns up to a block of the input not yet read, and reports whether
+// the stream has ended, in which case reading what is buffered reaches its
+// end.
+func (p *pipeReader) state() ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sample := p.buf.Bytes()
+	return bytes.Clone(sample[:min(len(sample), blockSize)]), p.err != nil
+}
+
+// failure returns the error that ended the stream, unless it was EOF.
+func (p *pipeReader) failure() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == io.EOF {
+		return nil
+	}
+	return p.err
+}
@@ -0,0 +1,142 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	gloo "github.com/gloo-foo/framework"
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// startStream runs cmd in the background reading stdin, and returns its
+// output buffer and a channel that receives its error when it returns.
+func startStream(ctx context.Context, cmd gloo.Command, stdin io.Reader) (*syncBuffer, <-chan error) {
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Executor()(ctx, stdin, out, io.Discard) }()
+	return out, done
+}
+
+// waitForExit waits for a command started by startStream to return.
+func waitForExit(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the command to return")
+		return nil
+	}
+}
+
+// ==============================================================================
+// Test Streaming Stdin and Pipes
+// ==============================================================================
+
+func TestTail_StdinHoldsOnlyLastRecords(t *testing.T) {
+	var in strings.Builder
+	for i := 1; i <= 1000; i++ {
+		parity := "odd"
+		if i%2 == 0 {
+			parity = "even"
+		}
+		fmt.Fprintf(&in, "%d %s\n", i, parity)
+	}
+
+	var out bytes.Buffer
+	err := command.Tail(command.LineCount(3), command.IncludePattern("even")).Executor()(
+		context.Background(), strings.NewReader(in.String()), &out, io.Discard)
+
+	assertion.NoError(t, err)
+	assertion.Equal(t, out.String(), "996 even\n998 even\n1000 even\n", "output")
+}
+
+func TestTail_FollowPipeStreamsLive(t *testing.T) {
+	r, w := io.Pipe()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(command.LineCount(2), command.Follow, command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "a\nb\nc\n")
+	waitForOutput(t, out, "b\nc\n")
+	io.WriteString(w, "d\n")
+	waitForOutput(t, out, "b\nc\nd\n")
+	io.WriteString(w, "e\n")
+	waitForOutput(t, out, "b\nc\nd\ne\n")
+	w.Close()
+
+	assertion.NoError(t, waitForExit(t, done))
+	assertion.Equal(t, out.String(), "b\nc\nd\ne\n", "output")
+}
+
+func TestTail_FollowPipeTimestampsLiveRecords(t *testing.T) {
+	r, w := io.Pipe()
+	clock := fixedClock{now: time.UnixMilli(42)}
+
+	out, done := startStream(context.Background(), command.Tail(command.Follow, command.TimestampUnixMillis, command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "held\n")
+	waitForOutput(t, out, "held\n")
+	io.WriteString(w, "live\n")
+	waitForOutput(t, out, "held\n42 live\n")
+	w.Close()
+
+	assertion.NoError(t, waitForExit(t, done))
+}
+
+func TestTail_FollowPipeFiltersLiveRecords(t *testing.T) {
+	r, w := io.Pipe()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(command.Follow, command.ExcludePattern("noise"), command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "first\nnoise\n")
+	waitForOutput(t, out, "first\n")
+	io.WriteString(w, "noise\nsecond\n")
+	w.Close()
+
+	assertion.NoError(t, waitForExit(t, done))
+	assertion.Equal(t, out.String(), "first\nsecond\n", "output")
+}
+
+func TestTail_FollowPipeEndsWithPartialLine(t *testing.T) {
+	r, w := io.Pipe()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(command.Follow, command.PartialComplete, command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "a\n")
+	waitForOutput(t, out, "a\n")
+	io.WriteString(w, "no newline")
+	w.Close()
+
+	assertion.NoError(t, waitForExit(t, done))
+	assertion.Equal(t, out.String(), "a\nno newline", "output")
+}
+
+func TestTail_FollowPipeReportsReadError(t *testing.T) {
+	r, w := io.Pipe()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	_, done := startStream(context.Background(), command.Tail(command.Follow, command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "a\n")
+	w.CloseWithError(io.ErrUnexpectedEOF)
+
+	assertion.ErrorContains(t, waitForExit(t, done), io.ErrUnexpectedEOF.Error())
+}
+
+func TestTail_FollowPipeStopsOnCancel(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, done := startStream(ctx, command.Tail(command.Follow, command.ClockFlag{Clock: clock}), r)
+	io.WriteString(w, "a\n")
+	waitForOutput(t, out, "a\n")
+	cancel()
+
+	assertion.NoError(t, waitForExit(t, done))
+}
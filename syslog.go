@@ -0,0 +1,175 @@
+package command
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// syslogFacilities names the facility codes of a syslog priority.
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "audit", "alert", "clock",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// syslogSeverities names the severity codes of a syslog priority, most
+// severe first. levelRanks orders them.
+var syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// rfc3164Pattern matches a BSD syslog line as syslog daemons write it, with
+// or without its priority, and with a classic or RFC 3339 timestamp:
+// "<PRI>Oct 17 10:00:00 HOST APP[PID]: MSG".
+var rfc3164Pattern = regexp.MustCompile(
+	`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d|\d{4}-\d\d-\d\dT\S+) (\S+) (?:([^\s\[\]:]+)(?:\[([^\]]*)\])?:(?: |$))?(.*)$`)
+
+// parseSyslog parses an RFC 5424 or RFC 3164 syslog line into the fields
+// facility, severity, time, host, app, pid and msg, and for RFC 5424 also
+// msgid and sd, the structured data by SD-ID and parameter name. Fields
+// the line lacks are left out.
+func parseSyslog(record string) (fields, bool) {
+	if f, ok := parseRFC5424(record); ok {
+		return f, true
+	}
+	m := rfc3164Pattern.FindStringSubmatch(record)
+	if m == nil {
+		return nil, false
+	}
+	f := fields{}
+	if m[1] != "" && !f.setPriority(m[1]) {
+		return nil, false
+	}
+	f.set("time", m[2])
+	f.set("host", m[3])
+	f.set("app", m[4])
+	f.set("pid", m[5])
+	f["msg"] = m[6]
+	return f, true
+}
+
+// parseRFC5424 parses
+// "<PRI>1 TIMESTAMP HOST APP PROCID MSGID STRUCTURED-DATA MSG", where "-"
+// stands for a missing value.
+func parseRFC5424(record string) (fields, bool) {
+	priority, rest, ok := strings.Cut(strings.TrimPrefix(record, "<"), ">1 ")
+	if !ok || !strings.HasPrefix(record, "<") {
+		return nil, false
+	}
+	f := fields{}
+	parts := strings.SplitN(rest, " ", 6)
+	if len(parts) < 6 || !f.setPriority(priority) {
+		return nil, false
+	}
+	sd, msg, ok := parseStructuredData(parts[5])
+	if !ok {
+		return nil, false
+	}
+	for i, name := range []string{"time", "host", "app", "pid", "msgid"} {
+		if parts[i] != "-" {
+			f.set(name, parts[i])
+		}
+	}
+	if len(sd) > 0 {
+		f["sd"] = sd
+	}
+	f["msg"] = strings.TrimPrefix(msg, "\uFEFF")
+	return f, true
+}
+
+// parseStructuredData parses the structured data that starts s, such as
+// `[origin ip="192.0.2.1"][meta seq="3"]`, or "-" for none, and returns it
+// with the message that follows it.
+func parseStructuredData(s string) (map[string]any, string, bool) {
+	sd := map[string]any{}
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		msg, ok := cutMessage(rest)
+		return sd, msg, ok
+	}
+	for strings.HasPrefix(s, "[") {
+		end := strings.IndexAny(s, " ]")
+		if end < 0 {
+			return nil, "", false
+		}
+		params := map[string]any{}
+		sd[s[1:end]] = params
+		s = s[end:]
+		for strings.HasPrefix(s, " ") {
+			name, rest, ok := strings.Cut(s[1:], `="`)
+			if !ok {
+				return nil, "", false
+			}
+			value, n, ok := unquoteParam(rest)
+			if !ok {
+				return nil, "", false
+			}
+			params[name] = value
+			s = rest[n:]
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", false
+		}
+		s = s[1:]
+	}
+	msg, ok := cutMessage(s)
+	return sd, msg, ok && len(sd) > 0
+}
+
+// unquoteParam reads a parameter value up to its closing quote, undoing the
+// escapes of '"', '\' and ']', and returns it with the length read.
+func unquoteParam(s string) (string, int, bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return b.String(), i + 1, true
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0:
+			i++
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// cutMessage returns the message after the space that follows the header,
+// if there is one.
+func cutMessage(s string) (string, bool) {
+	if s == "" {
+		return "", true
+	}
+	return strings.CutPrefix(s, " ")
+}
+
+// setPriority sets the facility and severity a syslog priority encodes.
+func (f fields) setPriority(priority string) bool {
+	n, err := strconv.Atoi(priority)
+	if err != nil || n < 0 || n >= len(syslogFacilities)*8 {
+		return false
+	}
+	f["facility"] = syslogFacilities[n/8]
+	f["severity"] = syslogSeverities[n%8]
+	return true
+}
+
+// set sets a field unless its value is empty.
+func (f fields) set(name, value string) {
+	if value != "" {
+		f[name] = value
+	}
+}
+
+// appSelected reports whether a syslog record's app matches an App, or
+// there are none.
+func (e *engine) appSelected(f fields) bool {
+	if len(e.Apps) == 0 {
+		return true
+	}
+	app, _ := f.lookup("app")
+	for _, pattern := range e.Apps {
+		if matchName(string(pattern), fieldString(app)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/gloo-foo/testable/assertion"
+	"github.com/gloo-foo/testable/run"
+	command "github.com/yupsh/tail"
+)
+
+var syslogLines = []string{
+	"<38>Oct 17 10:00:01 web1 sshd[812]: Accepted publickey for deploy",
+	"<11>Oct 17 10:00:02 web1 nginx: upstream timed out",
+	"-- MARK --",
+	"<30>2026-10-17T10:00:03.120+00:00 web1 systemd[1]: Started Session 42.",
+	`<165>1 2026-10-17T10:00:04.003Z web1 payments 4242 TX9 [origin ip="192.0.2.1"][meta seq="3" note="a \"quoted\" \] value"] card declined`,
+	"<12>1 2026-10-17T10:00:05Z web1 cron-job - - - \uFEFFnightly run slow",
+	"Oct 17 10:00:06 web1 kernel: eth0 link up",
+}
+
+// ==============================================================================
+// Test Syslog Parsing
+// ==============================================================================
+
+func TestTail_SyslogFields(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatSyslog, command.DropUnparsed,
+		command.Template("{{.facility}}.{{.severity}} {{.host}} {{.app}}[{{.pid}}] {{.msg}}"))).
+		WithStdinLines(syslogLines[:2]...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		"auth.info web1 sshd[812] Accepted publickey for deploy",
+		"user.err web1 nginx[<no value>] upstream timed out",
+	})
+}
+
+func TestTail_SyslogMinSeverity(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatSyslog, command.MinSeverity("warning"))).
+		WithStdinLines(syslogLines...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		syslogLines[1],
+		"-- MARK --",
+		syslogLines[5],
+	})
+}
+
+func TestTail_SyslogApp(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatSyslog, command.DropUnparsed, command.App("sshd"), command.App("cron-*"))).
+		WithStdinLines(syslogLines...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{syslogLines[0], syslogLines[5]})
+}
+
+func TestTail_SyslogOutputJSON(t *testing.T) {
+	result := run.Command(command.Tail(command.LineCount(4), command.FormatSyslog, command.OutputJSON)).
+		WithStdinLines(syslogLines...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{
+		`{"app":"systemd","facility":"daemon","host":"web1","msg":"Started Session 42.","pid":"1","severity":"info","time":"2026-10-17T10:00:03.120+00:00"}`,
+		`{"app":"payments","facility":"local4","host":"web1","msg":"card declined","msgid":"TX9","pid":"4242",` +
+			`"sd":{"meta":{"note":"a \"quoted\" ] value","seq":"3"},"origin":{"ip":"192.0.2.1"}},"severity":"notice","time":"2026-10-17T10:00:04.003Z"}`,
+		`{"app":"cron-job","facility":"user","host":"web1","msg":"nightly run slow","severity":"warning","time":"2026-10-17T10:00:05Z"}`,
+		`{"app":"kernel","host":"web1","msg":"eth0 link up","time":"Oct 17 10:00:06"}`,
+	})
+}
+
+func TestTail_SyslogStructuredDataFields(t *testing.T) {
+	result := run.Command(command.Tail(command.FormatSyslog, command.Where("sd.meta.seq>=3"),
+		command.Field("app"), command.Field("sd.origin.ip"))).
+		WithStdinLines(syslogLines...).
+		Run()
+
+	assertion.NoError(t, result.Err)
+	assertion.Lines(t, result.Stdout, []string{"-- MARK --", `{"app":"payments","sd.origin.ip":"192.0.2.1"}`})
+}
+
+func TestTail_SyslogNeedsFormat(t *testing.T) {
+	result := run.Quick(command.Tail(command.FormatJSON, command.MinSeverity("err")))
+
+	assertion.ErrorContains(t, result.Err, "MinSeverity and App need FormatSyslog")
+}
+
+func TestTail_SyslogUnknownSeverity(t *testing.T) {
+	result := run.Quick(command.Tail(command.FormatSyslog, command.MinSeverity("loud")))
+
+	assertion.ErrorContains(t, result.Err, `unknown severity "loud"`)
+}
+
+func TestTail_OutputJSONNeedsFormat(t *testing.T) {
+	result := run.Quick(command.Tail(command.OutputJSON))
+
+	assertion.ErrorContains(t, result.Err, "need a Format")
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	// ErrWaitTimeout is returned when no record matches WaitFor within
+	// WaitTimeout.
+	ErrWaitTimeout = errors.New("timed out waiting for a match")
+
+	// ErrNoMatch is returned when a stream followed with WaitFor ends
+	// without a record matching.
+	ErrNoMatch = errors.New("input ended without a match")
+)
+
+// Wait runs Tail with the given parameters, which must include WaitFor, and
+// returns the record that matched, redacted. Records are written to stdout as
+// they are followed.
+func Wait(ctx context.Context, stdin io.Reader, stdout io.Writer, parameters ...any) (string, error) {
+	p := Tail(parameters...).(command)
+	e, err := newEngine(p)
+	if err != nil {
+		return "", err
+	}
+	if e.wait == nil {
+		return "", errors.New("Wait needs a WaitFor pattern")
+	}
+	if err := p.executor(e)(ctx, stdin, stdout, io.Discard); err != nil {
+		return "", err
+	}
+	return e.redact(e.matched), nil
+}
+
+// untilMatch returns the records up to and including the first that matches
+// WaitFor, and reports whether one did.
+func (e *engine) untilMatch(records []string) ([]string, bool) {
+	if e.wait == nil {
+		return records, false
+	}
+	for i, record := range records {
+		if e.wait.MatchString(e.text(record)) {
+			e.matched, e.done = record, true
+			return records[:i+1], true
+		}
+	}
+	return records, false
+}
+
+// scanExisting looks for a match among the records of a seekable input up
+// to end when WaitScanExisting is set. Nothing is written.
+func (e *engine) scanExisting(r io.ReaderAt, end int64, decoder lineDecoder) error {
+	if e.wait == nil || !e.WaitScanExisting {
+		return nil
+	}
+	f := newForwardReader(r, 0, end, decoder)
+	g := e.newGrouper()
+	for !e.done {
+		line, _, ok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			e.untilMatch(e.filter(g.flush()))
+			return nil
+		}
+		e.untilMatch(e.filter(g.add([]string{line}, time.Time{})))
+	}
+	return nil
+}
+
+// waitExpired returns ErrWaitTimeout once WaitTimeout has passed since
+// following started at start.
+func (e *engine) waitExpired(start, now time.Time) error {
+	if e.wait == nil || e.WaitTimeout <= 0 || now.Sub(start) < time.Duration(e.WaitTimeout) {
+		return nil
+	}
+	return fmt.Errorf("%w: no record matched %q within %s", ErrWaitTimeout, e.WaitFor, time.Duration(e.WaitTimeout))
+}
+
+// stopped returns the error for following that ctx cancelled: none, unless a
+// match was awaited.
+func (e *engine) stopped(ctx context.Context) error {
+	if e.wait == nil {
+		return nil
+	}
+	return ctx.Err()
+}
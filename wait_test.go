@@ -0,0 +1,214 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gloo-foo/testable/assertion"
+	command "github.com/yupsh/tail"
+)
+
+// waitResult is what command.Wait returned.
+type waitResult struct {
+	line string
+	err  error
+}
+
+// startWait runs command.Wait in the background and returns its output
+// buffer and a channel that receives its result.
+func startWait(ctx context.Context, stdin io.Reader, parameters ...any) (*syncBuffer, <-chan waitResult) {
+	out := &syncBuffer{}
+	done := make(chan waitResult, 1)
+	go func() {
+		line, err := command.Wait(ctx, stdin, out, parameters...)
+		done <- waitResult{line, err}
+	}()
+	return out, done
+}
+
+// waitForResult waits for a command.Wait started by startWait to return.
+func waitForResult(t *testing.T, done <-chan waitResult) waitResult {
+	t.Helper()
+	select {
+	case result := <-done:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Wait to return")
+		return waitResult{}
+	}
+}
+
+// ==============================================================================
+// Test Waiting for a Match
+// ==============================================================================
+
+func TestWait_ReturnsMatchingLine(t *testing.T) {
+	path := writeFile(t, "booting\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), nil, path, command.WaitFor("listening on"), command.ClockFlag{Clock: clock})
+	waitForLines(t, out, 1)
+	appendFile(t, path, "loading\nlistening on :8080\nserving\n")
+	result := waitForResult(t, done)
+
+	assertion.NoError(t, result.err)
+	assertion.Equal(t, result.line, "listening on :8080", "matched line")
+	assertion.Equal(t, out.String(), "booting\nloading\nlistening on :8080\n", "output")
+}
+
+func TestWait_MatchesBeyondMaxLineLength(t *testing.T) {
+	path := writeFile(t, "boot\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), nil, path, command.WaitFor("listening"),
+		command.MaxLineLength(6), command.ClockFlag{Clock: clock})
+	waitForLines(t, out, 1)
+	appendFile(t, path, "server listening\n")
+	result := waitForResult(t, done)
+
+	assertion.NoError(t, result.err)
+	assertion.Equal(t, result.line, "server listening", "matched line")
+	assertion.Equal(t, out.String(), "boot\nserver…[+10 bytes]\n", "output")
+}
+
+func TestTail_WaitForEndsCommand(t *testing.T) {
+	path := writeFile(t, "")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	out, done := startStream(context.Background(), command.Tail(path, command.WaitFor("^ready$"),
+		command.ClockFlag{Clock: clock}), strings.NewReader(""))
+	clock.WaitForPolls(t, 2)
+	appendFile(t, path, "not ready\nready\n")
+
+	assertion.NoError(t, waitForExit(t, done))
+	assertion.Equal(t, out.String(), "not ready\nready\n", "output")
+}
+
+func TestWait_Timeout(t *testing.T) {
+	path := writeFile(t, "booting\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	_, done := startWait(context.Background(), nil, path, command.WaitFor("listening"),
+		command.WaitTimeout(5*time.Second), command.ClockFlag{Clock: clock})
+	clock.WaitForPolls(t, 2)
+	clock.Advance(4 * time.Second)
+	clock.WaitForPolls(t, 2)
+	select {
+	case result := <-done:
+		t.Fatalf("Wait returned before the timeout: %v", result.err)
+	default:
+	}
+	clock.Advance(time.Second)
+	result := waitForResult(t, done)
+
+	assertion.Equal(t, errors.Is(result.err, command.ErrWaitTimeout), true, "errors.Is ErrWaitTimeout")
+	assertion.ErrorContains(t, result.err, `no record matched "listening" within 5s`)
+}
+
+func TestWait_IgnoresExistingContentByDefault(t *testing.T) {
+	path := writeFile(t, "ready\n")
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	_, done := startWait(context.Background(), nil, path, command.WaitFor("ready"),
+		command.WaitTimeout(time.Second), command.ClockFlag{Clock: clock})
+	clock.WaitForPolls(t, 2)
+	clock.Advance(time.Second)
+	result := waitForResult(t, done)
+
+	assertion.Equal(t, errors.Is(result.err, command.ErrWaitTimeout), true, "errors.Is ErrWaitTimeout")
+}
+
+func TestWait_ScanExisting(t *testing.T) {
+	path := writeFile(t, numbered("line", 50)+"ready at line 51\n"+numbered("after", 20))
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), nil, path, command.WaitFor("ready"), command.WaitScanExisting,
+		command.LineCount(2), command.ClockFlag{Clock: clock})
+	result := waitForResult(t, done)
+
+	assertion.NoError(t, result.err)
+	assertion.Equal(t, result.line, "ready at line 51", "matched line")
+	assertion.Equal(t, out.String(), "after 19\nafter 20\n", "output")
+}
+
+func TestWait_ScanExistingAppliesFilter(t *testing.T) {
+	path := writeFile(t, "DEBUG ready\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), nil, path, command.WaitFor("ready"), command.WaitScanExisting,
+		command.ExcludePattern("DEBUG"), command.ClockFlag{Clock: clock})
+	appendFile(t, path, "INFO ready\n")
+	result := waitForResult(t, done)
+
+	assertion.NoError(t, result.err)
+	assertion.Equal(t, result.line, "INFO ready", "matched line")
+	assertion.Equal(t, out.String(), "INFO ready\n", "output")
+}
+
+func TestWait_Pipe(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), r, command.WaitFor("listening"), command.ClockFlag{Clock: clock})
+	io.WriteString(w, "booting\n")
+	waitForOutput(t, out, "booting\n")
+	io.WriteString(w, "listening on :8080\nserving\n")
+	result := waitForResult(t, done)
+
+	assertion.NoError(t, result.err)
+	assertion.Equal(t, result.line, "listening on :8080", "matched line")
+	assertion.Equal(t, out.String(), "booting\nlistening on :8080\n", "output")
+}
+
+func TestWait_PipeScanExistingBeforePause(t *testing.T) {
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), strings.NewReader("a\nb\nready\nc\n"), command.WaitFor("ready"),
+		command.WaitScanExisting, command.LineCount(2), command.ClockFlag{Clock: clock})
+	result := waitForResult(t, done)
+
+	assertion.NoError(t, result.err)
+	assertion.Equal(t, result.line, "ready", "matched line")
+	assertion.Equal(t, out.String(), "b\nready\n", "output")
+}
+
+func TestWait_PipeEndsWithoutMatch(t *testing.T) {
+	clock := fixedClock{now: time.Unix(0, 0)}
+
+	out, done := startWait(context.Background(), strings.NewReader("a\nb\n"), command.WaitFor("ready"),
+		command.ClockFlag{Clock: clock})
+	result := waitForResult(t, done)
+
+	assertion.Equal(t, errors.Is(result.err, command.ErrNoMatch), true, "errors.Is ErrNoMatch")
+	assertion.Equal(t, out.String(), "a\nb\n", "output")
+}
+
+func TestWait_Cancelled(t *testing.T) {
+	path := writeFile(t, "booting\n")
+	clock := fixedClock{now: time.Unix(0, 0)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	out, done := startWait(ctx, nil, path, command.WaitFor("listening"), command.ClockFlag{Clock: clock})
+	waitForLines(t, out, 1)
+	cancel()
+	result := waitForResult(t, done)
+
+	assertion.Equal(t, errors.Is(result.err, context.Canceled), true, "errors.Is context.Canceled")
+}
+
+func TestWait_NeedsPattern(t *testing.T) {
+	_, err := command.Wait(context.Background(), nil, io.Discard, writeFile(t, "a\n"))
+
+	assertion.ErrorContains(t, err, "Wait needs a WaitFor pattern")
+}
+
+func TestWait_InvalidPattern(t *testing.T) {
+	_, err := command.Wait(context.Background(), nil, io.Discard, writeFile(t, "a\n"), command.WaitFor("("))
+
+	assertion.ErrorContains(t, err, "missing closing )")
+}